@@ -2,6 +2,8 @@ package acceptance_test
 
 import (
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
 	"github.com/onsi/gomega/gexec"
 	"testing"
@@ -35,6 +37,13 @@ func runCliWithInput(path string, input io.Reader, args ...string) *gexec.Sessio
 	return startSession(cmd)
 }
 
+func runCliWithEnv(path string, env []string, args ...string) *gexec.Session {
+	cmd := exec.Command(path, args...)
+	cmd.Env = append(os.Environ(), env...)
+
+	return startSession(cmd)
+}
+
 func startSession(cmd *exec.Cmd) *gexec.Session {
 	session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
 	Expect(err).NotTo(HaveOccurred())
@@ -42,3 +51,14 @@ func startSession(cmd *exec.Cmd) *gexec.Session {
 
 	return session
 }
+
+func writeSessionConfig(rootHref string) {
+	config := "session-token: someToken\nroot-href: " + rootHref + "\n"
+	err := ioutil.WriteFile("test-config.yml", []byte(config), 0644)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+// Commands keep their mirror, queue and cache next to the config file.
+var _ = AfterEach(func() {
+	os.RemoveAll("./.doer-cli")
+})
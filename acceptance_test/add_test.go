@@ -0,0 +1,43 @@
+package acceptance_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("add", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var list *listServer
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		list = newListServer(server, "firstTask")
+	})
+
+	It("adds a todo to the now list and shows the difference", func() {
+		session = runCli(cliPath, "add", "new", "task", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "new task"}))
+		Expect(session).Should(gbytes.Say("  firstTask\n"))
+		Expect(session).Should(gbytes.Say(`\+ new task`))
+	})
+
+	It("adds a todo to the later list with --later", func() {
+		session = runCli(cliPath, "add", "laterTask", "--later", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.DeferredTodos()).To(Equal([]string{"laterTask"}))
+		Expect(list.Todos()).To(Equal([]string{"firstTask"}))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
@@ -0,0 +1,195 @@
+package acceptance_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("backup and restore", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var target *ghttp.Server
+	var cliPath string
+
+	runFailingRestore := func() *gexec.Session {
+		session, err := gexec.Start(exec.Command(cliPath, "restore", "test-backup.tar.gz", "--api", target.URL(), "--config", "test-config.yml"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(1))
+		return session
+	}
+
+	archivedFile := func(name string) []byte {
+		contents, err := ioutil.ReadFile("test-backup.tar.gz")
+		Expect(err).NotTo(HaveOccurred())
+		compressed, err := gzip.NewReader(bytes.NewReader(contents))
+		Expect(err).NotTo(HaveOccurred())
+		archive := tar.NewReader(compressed)
+		for {
+			header, err := archive.Next()
+			Expect(err).NotTo(HaveOccurred())
+			if header.Name == name {
+				body, err := ioutil.ReadAll(archive)
+				Expect(err).NotTo(HaveOccurred())
+				return body
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		target = ghttp.NewServer()
+		list := newListServer(server, "firstTask", "secondTask")
+		list.deferredTodos = []string{"laterTask"}
+		list.SetCompleted(cmd.CompletedTodo{Task: "doneTask", CompletedAt: time.Date(2019, 5, 2, 12, 0, 0, 0, time.UTC)})
+		session = runCli(cliPath, "backup", "test-backup.tar.gz", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("Backed up 1 lists to test-backup.tar.gz"))
+	})
+
+	It("restores the lists on another server", func() {
+		restored := newListServer(target)
+		session = runCli(cliPath, "restore", "test-backup.tar.gz", "--api", target.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("default: 3 added, 1 completed, 0 already there"))
+		Expect(restored.Todos()).To(Equal([]string{"firstTask", "secondTask"}))
+		Expect(restored.DeferredTodos()).To(Equal([]string{"laterTask"}))
+	})
+
+	It("does not add todos the list already has", func() {
+		restored := newListServer(target, "firstTask")
+		runCli(cliPath, "restore", "test-backup.tar.gz", "--api", target.URL(), "--config", "test-config.yml")
+		session = runCli(cliPath, "restore", "test-backup.tar.gz", "--api", target.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("default: 0 added, 0 completed, 4 already there"))
+		Expect(restored.Todos()).To(Equal([]string{"firstTask", "secondTask"}))
+	})
+
+	It("fails when the restore stops part way", func() {
+		restored := newListServer(target)
+		target.RouteToHandler("POST", "/createHref", ghttp.RespondWith(http.StatusBadRequest, nil))
+		session = runFailingRestore()
+		Expect(session.Err).Should(gbytes.Say("restore stopped at the default list"))
+		Expect(restored.Todos()).To(BeEmpty())
+	})
+
+	It("backs up the other lists once each, marking those without a completed history", func() {
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		links["lists"] = cmd.Link{Href: server.URL() + "/listsHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		defaultLinks := map[string]cmd.Link{"list": {Href: server.URL() + "/listHref"}}
+		projectLinks := map[string]cmd.Link{"list": {Href: server.URL() + "/projectHref"}}
+		server.RouteToHandler("GET", "/listsHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListsResponse{
+			Lists: []cmd.ListSummary{
+				{Name: "default", Links: defaultLinks},
+				{Name: "project-x", Links: projectLinks},
+			},
+		}))
+		server.RouteToHandler("GET", "/projectHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{
+			Todos: []cmd.Todo{{Task: "projectTask"}},
+		}}))
+		session = runCli(cliPath, "backup", "test-backup.tar.gz", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("Backed up 2 lists to test-backup.tar.gz"))
+		Expect(session.Err).Should(gbytes.Say(`list has no completed history.*list=project-x`))
+
+		var manifest cmd.Manifest
+		Expect(json.Unmarshal(archivedFile("manifest.json"), &manifest)).To(Succeed())
+		Expect(manifest.Lists).To(HaveLen(2))
+		Expect(manifest.Lists[0].Name).To(Equal(""))
+		Expect(manifest.Lists[0].CompletedMissing).To(BeFalse())
+		Expect(manifest.Lists[1].Name).To(Equal("project-x"))
+		Expect(manifest.Lists[1].CompletedMissing).To(BeTrue())
+	})
+
+	It("fails when the lists cannot be fetched", func() {
+		os.Remove("test-backup.tar.gz")
+		server.RouteToHandler("GET", "/listHref", ghttp.RespondWith(http.StatusInternalServerError, nil))
+		session, err := gexec.Start(exec.Command(cliPath, "backup", "test-backup.tar.gz", "--retries", "0", "--api", server.URL(), "--config", "test-config.yml"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(1))
+		Expect(session.Err).Should(gbytes.Say("request failed"))
+		Expect("test-backup.tar.gz").NotTo(BeAnExistingFile())
+	})
+
+	It("leaves out completed todos of a list without a completed history", func() {
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		links["lists"] = cmd.Link{Href: server.URL() + "/listsHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		projectLinks := map[string]cmd.Link{"list": {Href: server.URL() + "/projectHref"}}
+		server.RouteToHandler("GET", "/listsHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListsResponse{
+			Lists: []cmd.ListSummary{{Name: "project-x", Links: projectLinks}},
+		}))
+		server.RouteToHandler("GET", "/projectHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{
+			Links: map[string]cmd.Link{"completed": {Href: server.URL() + "/completedHref"}},
+		}}))
+		runCli(cliPath, "backup", "test-backup.tar.gz", "--api", server.URL(), "--config", "test-config.yml")
+
+		newListServer(target)
+		targetLinks := make(map[string]cmd.Link)
+		targetLinks["list"] = cmd.Link{Href: target.URL() + "/listHref"}
+		targetLinks["lists"] = cmd.Link{Href: target.URL() + "/listsHref"}
+		target.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: targetLinks}))
+		targetProjectLinks := map[string]cmd.Link{"list": {Href: target.URL() + "/projectHref"}}
+		target.RouteToHandler("GET", "/listsHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListsResponse{
+			Lists: []cmd.ListSummary{{Name: "project-x", Links: targetProjectLinks}},
+		}))
+		target.RouteToHandler("GET", "/projectHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{}))
+		session = runCli(cliPath, "restore", "test-backup.tar.gz", "--api", target.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("project-x: 0 added, 0 completed, 0 already there"))
+		Expect(session.Err).Should(gbytes.Say("list has no completed history, leaving out its completed todos"))
+	})
+
+	It("refuses an archive that does not match its manifest", func() {
+		restored := newListServer(target)
+		contents, err := ioutil.ReadFile("test-backup.tar.gz")
+		Expect(err).NotTo(HaveOccurred())
+		compressed, err := gzip.NewReader(bytes.NewReader(contents))
+		Expect(err).NotTo(HaveOccurred())
+		archive := tar.NewReader(compressed)
+		var tampered bytes.Buffer
+		tamperedCompressed := gzip.NewWriter(&tampered)
+		tamperedArchive := tar.NewWriter(tamperedCompressed)
+		for {
+			header, err := archive.Next()
+			if err != nil {
+				break
+			}
+			body, err := ioutil.ReadAll(archive)
+			Expect(err).NotTo(HaveOccurred())
+			if header.Name != "manifest.json" {
+				body = bytes.Replace(body, []byte("firstTask"), []byte("otherTask"), 1)
+			}
+			Expect(tamperedArchive.WriteHeader(header)).To(Succeed())
+			_, err = tamperedArchive.Write(body)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(tamperedArchive.Close()).To(Succeed())
+		Expect(tamperedCompressed.Close()).To(Succeed())
+		Expect(ioutil.WriteFile("test-backup.tar.gz", tampered.Bytes(), 0600)).To(Succeed())
+
+		session = runFailingRestore()
+		Expect(session.Err).Should(gbytes.Say("checksum mismatch for lists/0.json"))
+		Expect(restored.Todos()).To(BeEmpty())
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.Remove("./test-backup.tar.gz")
+		server.Close()
+		target.Close()
+	})
+})
@@ -0,0 +1,112 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("cache", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	respondWithLockedList := func(header http.Header) {
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.RouteToHandler("GET", "/listHref", func(w http.ResponseWriter, r *http.Request) {
+			if header.Get("ETag") != "" && r.Header.Get("If-None-Match") == header.Get("ETag") {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later"}}, header)(w, r)
+		})
+	}
+
+	listRequests := func() []*http.Request {
+		requests := make([]*http.Request, 0)
+		for _, request := range server.ReceivedRequests() {
+			if request.URL.Path == "/listHref" {
+				requests = append(requests, request)
+			}
+		}
+		return requests
+	}
+
+	unlock := func(args ...string) {
+		args = append([]string{"unlock", "--api", server.URL(), "--config", "test-config.yml"}, args...)
+		session = runCli(cliPath, args...)
+		Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+	})
+
+	Context("with a response that has an ETag", func() {
+		BeforeEach(func() {
+			respondWithLockedList(http.Header{"Etag": []string{`"v1"`}})
+			unlock()
+		})
+
+		It("revalidates the cached response with If-None-Match", func() {
+			unlock()
+			requests := listRequests()
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[0].Header.Get("If-None-Match")).To(BeEmpty())
+			Expect(requests[1].Header.Get("If-None-Match")).To(Equal(`"v1"`))
+		})
+
+		It("does not use the cache with --no-cache", func() {
+			unlock("--no-cache")
+			Expect(listRequests()[1].Header.Get("If-None-Match")).To(BeEmpty())
+		})
+
+		It("forgets cached responses with cache clear", func() {
+			runCli(cliPath, "cache", "clear", "--config", "test-config.yml")
+			unlock()
+			Expect(listRequests()[1].Header.Get("If-None-Match")).To(BeEmpty())
+		})
+	})
+
+	Context("with a response that is fresh for a while", func() {
+		BeforeEach(func() {
+			respondWithLockedList(http.Header{"Cache-Control": []string{"max-age=60"}})
+			unlock()
+		})
+
+		It("serves the cached response without a request", func() {
+			unlock()
+			Expect(listRequests()).To(HaveLen(1))
+		})
+	})
+
+	Context("with a response that must not be stored", func() {
+		BeforeEach(func() {
+			respondWithLockedList(http.Header{"Cache-Control": []string{"no-store"}, "Etag": []string{`"v1"`}})
+			unlock()
+		})
+
+		It("fetches it again in full", func() {
+			unlock()
+			requests := listRequests()
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[1].Header.Get("If-None-Match")).To(BeEmpty())
+		})
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.RemoveAll("./.doer-cli")
+		server.Close()
+	})
+})
@@ -0,0 +1,118 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("cassettes", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var serverURL string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		serverURL = server.URL()
+	})
+
+	Context("with a recorded session", func() {
+		BeforeEach(func() {
+			writeSessionConfig(server.URL() + "/rootResourcesHref")
+			links := make(map[string]cmd.Link)
+			links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+			server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+			listLinks := make(map[string]cmd.Link)
+			listLinks["create"] = cmd.Link{Href: server.URL() + "/createHref"}
+			server.RouteToHandler("GET", "/listHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later", UnlockDuration: 90000, Links: listLinks}}))
+			session = runCli(cliPath, "unlock", "--record", "test-cassette.json", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(string(session.Out.Contents())).To(Equal("later list: unlocked, 1m30s remaining\n"))
+		})
+
+		It("scrubs the session token from the cassette", func() {
+			cassette, err := ioutil.ReadFile("test-cassette.json")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(cassette)).To(ContainSubstring("/listHref"))
+			Expect(string(cassette)).To(ContainSubstring("REDACTED"))
+			Expect(string(cassette)).NotTo(ContainSubstring("someToken"))
+		})
+
+		It("replays the session without the server", func() {
+			server.Close()
+			session = runCli(cliPath, "unlock", "--replay", "test-cassette.json", "--api", serverURL, "--config", "test-config.yml")
+			Expect(string(session.Out.Contents())).To(Equal("later list: unlocked, 1m30s remaining\n"))
+		})
+
+		It("fails requests that were not recorded", func() {
+			server.Close()
+			session = runCli(cliPath, "login", "--replay", "test-cassette.json", "--api", serverURL, "--config", "test-config.yml")
+			Expect(session.Err).Should(gbytes.Say("no recorded response for GET /v1/"))
+		})
+
+		It("reports requests that were not recorded instead of working offline", func() {
+			server.Close()
+			session = runCli(cliPath, "add", "someTask", "--replay", "test-cassette.json", "--api", serverURL, "--config", "test-config.yml")
+			Expect(session.Err).Should(gbytes.Say("no recorded response"))
+			Expect(string(session.Err.Contents())).NotTo(ContainSubstring("working offline"))
+		})
+	})
+
+	Context("with a recorded login", func() {
+		BeforeEach(func() {
+			links := make(map[string]cmd.Link)
+			links["login"] = cmd.Link{Href: server.URL() + "/loginHref"}
+			server.RouteToHandler("GET", "/v1/", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+			rootLinks := make(map[string]cmd.Link)
+			rootLinks["root"] = cmd.Link{Href: server.URL() + "/rootResourcesHref"}
+			server.RouteToHandler("POST", "/loginHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.SessionResponse{
+				Session: cmd.Session{Token: "someToken"},
+				Links:   rootLinks,
+			}))
+			input := gbytes.BufferWithBytes([]byte("someEmail\nsomePassword\n"))
+			runCliWithInput(cliPath, input, "login", "--record", "test-cassette.json", "--api", server.URL(), "--config", "test-config.yml")
+			server.Close()
+		})
+
+		It("scrubs the password and the session token from the cassette", func() {
+			cassette, err := ioutil.ReadFile("test-cassette.json")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(cassette)).To(ContainSubstring("someEmail"))
+			Expect(string(cassette)).NotTo(ContainSubstring("somePassword"))
+			Expect(string(cassette)).NotTo(ContainSubstring("someToken"))
+		})
+
+		It("matches requests by method and URL by default", func() {
+			input := gbytes.BufferWithBytes([]byte("otherEmail\notherPassword\n"))
+			session = runCliWithInput(cliPath, input, "login", "--replay", "test-cassette.json", "--api", serverURL, "--config", "test-config.yml")
+			config, err := ioutil.ReadFile("test-config.yml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(config)).To(ContainSubstring("/rootResourcesHref"))
+		})
+
+		It("also matches request bodies, ignoring secrets, with --replay-match method-url-body", func() {
+			input := gbytes.BufferWithBytes([]byte("someEmail\notherPassword\n"))
+			session = runCliWithInput(cliPath, input, "login", "--replay", "test-cassette.json", "--replay-match", "method-url-body", "--api", serverURL, "--config", "test-config.yml")
+			Expect(string(session.Err.Contents())).NotTo(ContainSubstring("no recorded response"))
+
+			input = gbytes.BufferWithBytes([]byte("otherEmail\nsomePassword\n"))
+			session = runCliWithInput(cliPath, input, "login", "--replay", "test-cassette.json", "--replay-match", "method-url-body", "--api", serverURL, "--config", "test-config.yml")
+			Expect(session.Err).Should(gbytes.Say("no recorded response for POST /loginHref"))
+		})
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.Remove("./test-cassette.json")
+		server.Close()
+	})
+})
@@ -0,0 +1,60 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("colored output", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	args := []string{"unlock", "--config", "test-config.yml"}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.RouteToHandler("GET", "/listHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later"}}))
+	})
+
+	It("is plain when output is piped", func() {
+		session = runCliWithEnv(cliPath, []string{"NO_COLOR=", "CLICOLOR_FORCE="}, append(args, "--api", server.URL())...)
+		Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+	})
+
+	It("colors locked states when color is forced", func() {
+		session = runCliWithEnv(cliPath, []string{"NO_COLOR=", "TERM=xterm", "CLICOLOR_FORCE=1"}, append(args, "--api", server.URL())...)
+		Expect(string(session.Out.Contents())).To(Equal("\x1b[34mlater\x1b[0m list: \x1b[31mlocked\x1b[0m\n"))
+	})
+
+	It("respects NO_COLOR", func() {
+		session = runCliWithEnv(cliPath, []string{"NO_COLOR=1", "TERM=xterm", "CLICOLOR_FORCE=1"}, append(args, "--api", server.URL())...)
+		Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+	})
+
+	It("respects --no-color", func() {
+		session = runCliWithEnv(cliPath, []string{"NO_COLOR=", "TERM=xterm", "CLICOLOR_FORCE=1"}, append(args, "--no-color", "--api", server.URL())...)
+		Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+	})
+
+	It("respects TERM=dumb", func() {
+		session = runCliWithEnv(cliPath, []string{"NO_COLOR=", "TERM=dumb", "CLICOLOR_FORCE=1"}, append(args, "--api", server.URL())...)
+		Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
@@ -0,0 +1,42 @@
+package acceptance_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("complete", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var list *listServer
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		list = newListServer(server, "firstTask", "secondTask")
+	})
+
+	It("completes the todo with the given number and shows the difference", func() {
+		session = runCli(cliPath, "complete", "2", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"firstTask"}))
+		Expect(session).Should(gbytes.Say(`- secondTask`))
+	})
+
+	It("reports numbers that are not in the list", func() {
+		session = runCli(cliPath, "complete", "3", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say("no todo number 3 in the now list"))
+		Expect(list.Todos()).To(HaveLen(2))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
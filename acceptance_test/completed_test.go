@@ -0,0 +1,74 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("completed", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["completedList"] = cmd.Link{Href: server.URL() + "/completedListHref"}
+		firstPageLinks := make(map[string]cmd.Link)
+		firstPageLinks["next"] = cmd.Link{Href: server.URL() + "/completedListHref/2"}
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/rootResourcesHref"),
+				ghttp.VerifyHeaderKV("Session-Token", "someToken"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/completedListHref"),
+				ghttp.VerifyHeaderKV("Session-Token", "someToken"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.CompletedListResponse{
+					List: cmd.CompletedList{Todos: []cmd.CompletedTodo{
+						{Task: "firstTask", CompletedAt: time.Date(2019, 5, 2, 12, 0, 0, 0, time.UTC)},
+					}},
+					Links: firstPageLinks,
+				}),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/completedListHref/2"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.CompletedListResponse{
+					List: cmd.CompletedList{Todos: []cmd.CompletedTodo{
+						{Task: "secondTask", CompletedAt: time.Date(2019, 4, 20, 12, 0, 0, 0, time.UTC)},
+					}},
+				}),
+			),
+		)
+	})
+
+	It("lists completed todos from every page with their completion dates", func() {
+		session = runCli(cliPath, "completed", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(server.ReceivedRequests()).Should(HaveLen(3))
+		Expect(session).Should(gbytes.Say("2019-05-02 .*firstTask"))
+		Expect(session).Should(gbytes.Say("2019-04-20 .*secondTask"))
+	})
+
+	It("only lists todos completed within the requested dates", func() {
+		session = runCli(cliPath, "completed", "--since", "2019-05-01", "--until", "2019-05-31", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Out.Contents()).Should(ContainSubstring("firstTask"))
+		Expect(session.Out.Contents()).ShouldNot(ContainSubstring("secondTask"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
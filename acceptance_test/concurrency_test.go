@@ -0,0 +1,88 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("optimistic concurrency", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var list *listServer
+
+	lastPost := func() *http.Request {
+		var last *http.Request
+		for _, request := range server.ReceivedRequests() {
+			if request.Method == "POST" {
+				last = request
+			}
+		}
+		return last
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		list = newListServer(server, "firstTask", "secondTask")
+	})
+
+	It("sends the ETag of the list with changes to it", func() {
+		list.SetTodos("firstTask", "secondTask")
+		session = runCli(cliPath, "complete", "1", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(lastPost().Header.Get("If-Match")).To(Equal(`"v1"`))
+		Expect(list.Todos()).To(Equal([]string{"secondTask"}))
+	})
+
+	When("the list changes on the server before the change arrives", func() {
+		BeforeEach(func() {
+			list.SetTodosBeforeNextChange("secondTask", "firstTask", "raceTask")
+		})
+
+		It("shows what changed and retries the change when asked to", func() {
+			session = runCliWithInput(cliPath, gbytes.BufferWithBytes([]byte("y\n")), "complete", "1", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session).Should(gbytes.Say("The list was changed on the server:"))
+			Expect(session).Should(gbytes.Say(`\+ raceTask`))
+			Expect(session).Should(gbytes.Say(`Retry\? \[y/N\]`))
+			Expect(session).Should(gbytes.Say(`- firstTask`))
+			Expect(list.Todos()).To(Equal([]string{"secondTask", "raceTask"}))
+		})
+
+		It("leaves the list alone when the retry is declined", func() {
+			session = runCliWithInput(cliPath, gbytes.BufferWithBytes([]byte("n\n")), "complete", "1", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session).Should(gbytes.Say("Nothing was changed"))
+			Expect(list.Todos()).To(Equal([]string{"secondTask", "firstTask", "raceTask"}))
+		})
+
+		It("retries without asking with --strategy mine", func() {
+			session = runCli(cliPath, "complete", "1", "--strategy", "mine", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(list.Todos()).To(Equal([]string{"secondTask", "raceTask"}))
+		})
+	})
+
+	It("gives up when the server keeps refusing the change", func() {
+		server.RouteToHandler("POST", todoActionPath, ghttp.RespondWith(http.StatusPreconditionFailed, nil))
+		session = runCli(cliPath, "complete", "1", "--strategy", "mine", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say("412"))
+		posts := 0
+		for _, request := range server.ReceivedRequests() {
+			if request.Method == "POST" {
+				posts++
+			}
+		}
+		Expect(posts).To(Equal(6))
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "secondTask"}))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
@@ -0,0 +1,57 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("config file", func() {
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+	})
+
+	It("keeps the permissions of the config file", func() {
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		Expect(os.Chmod("test-config.yml", 0600)).To(Succeed())
+		runCli(cliPath, "cache", "clear", "--api", server.URL(), "--config", "test-config.yml")
+		info, err := os.Stat("test-config.yml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("creates a missing config file readable only by its owner", func() {
+		runCli(cliPath, "cache", "clear", "--api", server.URL(), "--config", "test-config.yml")
+		info, err := os.Stat("test-config.yml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("writes through a symlinked config file", func() {
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		Expect(os.Rename("test-config.yml", "test-config-target.yml")).To(Succeed())
+		Expect(os.Symlink("test-config-target.yml", "test-config.yml")).To(Succeed())
+		runCli(cliPath, "cache", "clear", "--api", server.URL(), "--config", "test-config.yml")
+		info, err := os.Lstat("test-config.yml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode() & os.ModeSymlink).NotTo(BeZero())
+		contents, err := ioutil.ReadFile("test-config-target.yml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring("server-url: " + server.URL()))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.Remove("./test-config-target.yml")
+		server.Close()
+	})
+})
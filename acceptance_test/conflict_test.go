@@ -0,0 +1,107 @@
+package acceptance_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("conflicts", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var list *listServer
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		list = newListServer(server, "firstTask", "secondTask", "thirdTask")
+		runCli(cliPath, "list", "--api", server.URL(), "--config", "test-config.yml")
+	})
+
+	It("reads todo numbers against the list as it was shown", func() {
+		list.SetTodos("thirdTask", "firstTask", "secondTask")
+		session = runCli(cliPath, "complete", "2", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"thirdTask", "firstTask"}))
+	})
+
+	It("applies moves that do not conflict with changes on the server", func() {
+		list.SetTodos("firstTask", "secondTask", "thirdTask", "newTask")
+		session = runCli(cliPath, "move", "3", "2", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "thirdTask", "secondTask", "newTask"}))
+	})
+
+	When("the todo was also moved on the server", func() {
+		BeforeEach(func() {
+			list.SetTodos("thirdTask", "firstTask", "secondTask")
+		})
+
+		It("keeps the server's version with --strategy theirs", func() {
+			session = runCli(cliPath, "move", "3", "2", "--strategy", "theirs", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session).Should(gbytes.Say("Kept the server's version, nothing was changed"))
+			Expect(list.Todos()).To(Equal([]string{"thirdTask", "firstTask", "secondTask"}))
+		})
+
+		It("applies the move with --strategy mine", func() {
+			session = runCli(cliPath, "move", "3", "2", "--strategy", "mine", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(list.Todos()).To(Equal([]string{"firstTask", "thirdTask", "secondTask"}))
+		})
+
+		It("asks how to resolve the conflict", func() {
+			session = runCliWithInput(cliPath, gbytes.BufferWithBytes([]byte("x\nm\n")), "move", "3", "2", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session).Should(gbytes.Say(`Conflict: "thirdTask" was moved to position 1 on the server`))
+			Expect(session).Should(gbytes.Say(`yours: move "thirdTask" to 2`))
+			Expect(session).Should(gbytes.Say("Please answer m, t or a"))
+			Expect(list.Todos()).To(Equal([]string{"firstTask", "thirdTask", "secondTask"}))
+		})
+
+		It("aborts when there is no answer", func() {
+			session = runCli(cliPath, "move", "3", "2", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session).Should(gbytes.Say("Aborted, nothing was changed"))
+			Expect(list.Todos()).To(Equal([]string{"thirdTask", "firstTask", "secondTask"}))
+		})
+	})
+
+	It("detects a todo that was also added on the server", func() {
+		list.SetTodos("firstTask", "secondTask", "thirdTask", "newTask")
+		session = runCli(cliPath, "add", "newTask", "--strategy", "abort", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("Aborted, nothing was changed"))
+		Expect(list.Todos()).To(HaveLen(4))
+	})
+
+	It("rejects unknown strategies", func() {
+		session = runCli(cliPath, "add", "newTask", "--strategy", "maybe", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say(`unknown --strategy`))
+		Expect(list.Todos()).To(HaveLen(3))
+	})
+
+	When("a queued move conflicts with a move on the server", func() {
+		BeforeEach(func() {
+			runCli(cliPath, "move", "3", "2", "--offline", "--api", server.URL(), "--config", "test-config.yml")
+			runCli(cliPath, "add", "offlineTask", "--offline", "--api", server.URL(), "--config", "test-config.yml")
+			list.SetTodos("thirdTask", "firstTask", "secondTask")
+		})
+
+		It("resolves it during sync with --strategy", func() {
+			session = runCli(cliPath, "sync", "--strategy", "theirs", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(string(session.Out.Contents())).To(Equal("applied add \"offlineTask\"\nskipped move \"thirdTask\" to 2: kept the server's version\n"))
+			Expect(list.Todos()).To(Equal([]string{"thirdTask", "firstTask", "secondTask", "offlineTask"}))
+		})
+
+		It("keeps the conflicting change and the ones after it queued when aborted", func() {
+			session = runCli(cliPath, "sync", "--strategy", "abort", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(string(session.Out.Contents())).To(Equal("pending move \"thirdTask\" to 2\npending add \"offlineTask\"\n"))
+			Expect(list.Todos()).To(HaveLen(3))
+		})
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
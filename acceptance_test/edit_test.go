@@ -0,0 +1,108 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("edit", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var list *listServer
+
+	// The editor keeps the file it was given in test-written.txt and
+	// replaces it with test-edited.txt.
+	edit := func(contents string) {
+		Expect(ioutil.WriteFile("test-edited.txt", []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		list = newListServer(server, "first", "second", "third", "fourth")
+		list.deferredTodos = []string{"later"}
+		dir, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		script := "#!/bin/sh\ncp \"$1\" " + filepath.Join(dir, "test-written.txt") + "\ncp " + filepath.Join(dir, "test-edited.txt") + " \"$1\"\n"
+		Expect(ioutil.WriteFile("test-editor.sh", []byte(script), 0700)).To(Succeed())
+		os.Setenv("EDITOR", filepath.Join(dir, "test-editor.sh"))
+	})
+
+	It("opens the list with an id for each todo", func() {
+		edit("")
+		runCli(cliPath, "edit", "--api", server.URL(), "--config", "test-config.yml")
+		written, err := ioutil.ReadFile("test-written.txt")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(written)).To(HaveSuffix("\n## now\n[1] first\n[2] second\n[3] third\n[4] fourth\n\n## later\n[5] later\n"))
+	})
+
+	It("reorders the list with the fewest moves", func() {
+		edit("## now\n[4] fourth\n[1] first\n[2] second\n[3] third\n## later\n[5] later\n")
+		session = runCli(cliPath, "edit", "--yes", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(HavePrefix("  move \"fourth\" to 1\n"))
+		Expect(list.Todos()).To(Equal([]string{"fourth", "first", "second", "third"}))
+	})
+
+	It("updates, deletes and adds todos", func() {
+		edit("## now\n[1] first changed\n[3] third\nnew\n[4] fourth\n## later\n[5] later\n")
+		session = runCli(cliPath, "edit", "--yes", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`  delete "second"\n  update "first" to "first changed"\n  add "new"\n  move "new" to 3\n`))
+		Expect(list.Todos()).To(Equal([]string{"first changed", "third", "new", "fourth"}))
+		Expect(session).Should(gbytes.Say(`\+ first changed`))
+	})
+
+	It("swaps the text of two todos", func() {
+		edit("## now\n[1] second\n[2] first\n[3] third\n[4] fourth\n## later\n[5] later\n")
+		runCli(cliPath, "edit", "--yes", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"second", "first", "third", "fourth"}))
+	})
+
+	It("changes the todo it was asked to among todos with the same text", func() {
+		list.SetTodos("same", "other", "same")
+		edit("## now\n[1] same\n[2] other\n[3] changed\n## later\n[4] later\n")
+		runCli(cliPath, "edit", "--yes", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"same", "other", "changed"}))
+		edit("## now\n[2] other\n[3] changed\n[1] same\n## later\n[4] later\n")
+		runCli(cliPath, "edit", "--yes", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"other", "changed", "same"}))
+	})
+
+	It("moves a todo between the now and later lists", func() {
+		edit("## now\n[2] second\n[3] third\n[4] fourth\n## later\n[5] later\n[1] first\n")
+		runCli(cliPath, "edit", "--yes", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"second", "third", "fourth"}))
+		Expect(list.DeferredTodos()).To(Equal([]string{"later", "first"}))
+	})
+
+	It("asks before changing anything", func() {
+		edit("## now\n[1] first\n## later\n")
+		input := gbytes.BufferWithBytes([]byte("n\n"))
+		session = runCliWithInput(cliPath, input, "edit", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`Apply these changes\? \[y/N\] Nothing was changed`))
+		Expect(list.Todos()).To(Equal([]string{"first", "second", "third", "fourth"}))
+	})
+
+	It("changes nothing when the list was not edited", func() {
+		edit("## now\n[1] first\n[2] second\n[3] third\n[4] fourth\n## later\n[5] later\n")
+		session = runCli(cliPath, "edit", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("Nothing to change"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Unsetenv("EDITOR")
+		os.Remove("./test-config.yml")
+		os.Remove("./test-editor.sh")
+		os.Remove("./test-edited.txt")
+		os.Remove("./test-written.txt")
+		server.Close()
+	})
+})
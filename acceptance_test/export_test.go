@@ -0,0 +1,130 @@
+package acceptance_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("export", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var list *listServer
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		list = newListServer(server, "firstTask", "secondTask")
+		list.deferredTodos = []string{"laterTask"}
+		list.SetCompleted(cmd.CompletedTodo{Task: "doneTask", CompletedAt: time.Date(2019, 5, 2, 12, 0, 0, 0, time.UTC)})
+	})
+
+	It("exports the now, later and completed todos as versioned JSON", func() {
+		session = runCli(cliPath, "export", "--api", server.URL(), "--config", "test-config.yml")
+		var export cmd.Export
+		Expect(json.Unmarshal(session.Out.Contents(), &export)).To(Succeed())
+		Expect(export.Version).To(Equal(1))
+		Expect(export.Todos).To(Equal([]string{"firstTask", "secondTask"}))
+		Expect(export.DeferredTodos).To(Equal([]string{"laterTask"}))
+		Expect(export.Completed).To(HaveLen(1))
+		Expect(export.Completed[0].Task).To(Equal("doneTask"))
+	})
+
+	It("does not export the default list's history for a list without one", func() {
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		links["lists"] = cmd.Link{Href: server.URL() + "/listsHref"}
+		links["completedList"] = cmd.Link{Href: server.URL() + "/completedHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		projectLinks := map[string]cmd.Link{"list": {Href: server.URL() + "/projectHref"}}
+		server.RouteToHandler("GET", "/listsHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListsResponse{
+			Lists: []cmd.ListSummary{{Name: "project-x", Links: projectLinks}},
+		}))
+		server.RouteToHandler("GET", "/projectHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{
+			Todos: []cmd.Todo{{Task: "projectTask"}},
+		}}))
+		session = runCli(cliPath, "export", "--list", "project-x", "--api", server.URL(), "--config", "test-config.yml")
+		var export cmd.Export
+		Expect(json.Unmarshal(session.Out.Contents(), &export)).To(Succeed())
+		Expect(export.Todos).To(Equal([]string{"projectTask"}))
+		Expect(export.Completed).To(BeEmpty())
+	})
+
+	It("leaves out parts of the list with --include", func() {
+		session = runCli(cliPath, "export", "--include", "later", "--api", server.URL(), "--config", "test-config.yml")
+		var export cmd.Export
+		Expect(json.Unmarshal(session.Out.Contents(), &export)).To(Succeed())
+		Expect(export.Todos).To(BeNil())
+		Expect(export.DeferredTodos).To(Equal([]string{"laterTask"}))
+		Expect(export.Completed).To(BeNil())
+	})
+
+	It("exports CSV that import reads back", func() {
+		session = runCli(cliPath, "export", "--format", "csv", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("task,later,completed,completedAt\n" +
+			"firstTask,,,\n" +
+			"secondTask,,,\n" +
+			"laterTask,x,,\n" +
+			"doneTask,,x,2019-05-02T12:00:00Z\n"))
+	})
+
+	It("exports a Markdown checklist", func() {
+		session = runCli(cliPath, "export", "--format", "markdown", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("## now\n\n- [ ] firstTask\n- [ ] secondTask\n\n" +
+			"## later\n\n- [ ] laterTask\n\n" +
+			"## completed\n\n- [x] doneTask\n"))
+	})
+
+	It("exports todo.txt", func() {
+		session = runCli(cliPath, "export", "--format", "todotxt", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("firstTask\nsecondTask\nlaterTask \\+later\nx 2019-05-0[23] doneTask\n"))
+	})
+
+	It("exports iCalendar todos", func() {
+		session = runCli(cliPath, "export", "--format", "ical", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+		Expect(session).Should(gbytes.Say("BEGIN:VTODO\r\nUID:[0-9a-f]+@doer-cli\r\nDTSTAMP:\\d{8}T\\d{6}Z\r\nSUMMARY:firstTask\r\nSTATUS:NEEDS-ACTION\r\nEND:VTODO\r\n"))
+		Expect(session).Should(gbytes.Say("SUMMARY:laterTask\r\nCATEGORIES:later\r\n"))
+		Expect(session).Should(gbytes.Say("SUMMARY:doneTask\r\nSTATUS:COMPLETED\r\nCOMPLETED:20190502T120000Z\r\n"))
+		Expect(session).Should(gbytes.Say("END:VCALENDAR\r\n$"))
+	})
+
+	It("keeps the iCalendar UID of a todo when the list changes around it", func() {
+		uid := regexp.MustCompile("UID:([0-9a-f]+@doer-cli)\r\nDTSTAMP:[0-9TZ]+\r\nSUMMARY:secondTask\r\n")
+		session = runCli(cliPath, "export", "--format", "ical", "--api", server.URL(), "--config", "test-config.yml")
+		before := uid.FindStringSubmatch(string(session.Out.Contents()))
+		Expect(before).NotTo(BeNil())
+		list.SetTodos("secondTask", "newTask")
+		list.SetCompleted(cmd.CompletedTodo{Task: "firstTask", CompletedAt: time.Date(2019, 5, 3, 12, 0, 0, 0, time.UTC)})
+		session = runCli(cliPath, "export", "--format", "ical", "--api", server.URL(), "--config", "test-config.yml")
+		after := uid.FindStringSubmatch(string(session.Out.Contents()))
+		Expect(after).NotTo(BeNil())
+		Expect(after[1]).To(Equal(before[1]))
+	})
+
+	It("writes the export to a file with --file", func() {
+		session = runCli(cliPath, "export", "--format", "markdown", "--file", "test-export.md", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("Exported 4 todos to test-export.md"))
+		contents, err := ioutil.ReadFile("test-export.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring("- [ ] firstTask\n"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.Remove("./test-export.md")
+		server.Close()
+	})
+})
@@ -0,0 +1,115 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("import", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var list *listServer
+
+	writeImportFile := func(name string, contents string) {
+		Expect(ioutil.WriteFile(name, []byte(contents), 0600)).To(Succeed())
+	}
+
+	runFailingImport := func(args ...string) *gexec.Session {
+		args = append([]string{"import"}, args...)
+		args = append(args, "--api", server.URL(), "--config", "test-config.yml")
+		session, err := gexec.Start(exec.Command(cliPath, args...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(1))
+		return session
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		list = newListServer(server, "firstTask")
+	})
+
+	It("imports a Markdown checklist, completing the checked items", func() {
+		writeImportFile("test-import.md", "# Chores\n\n- [ ] wash car\n- [x] buy milk\n* [ ] call mom\nnot a todo\n")
+		session = runCli(cliPath, "import", "test-import.md", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "wash car", "call mom"}))
+		Expect(session).Should(gbytes.Say(`\+ wash car\nx buy milk\n\+ call mom\nImported 3 todos\n`))
+	})
+
+	It("orders todo.txt lines by priority", func() {
+		writeImportFile("test-import.txt", "plain task\n(B) 2019-01-02 second\nx 2019-01-03 2019-01-01 done task\n(A) first\n")
+		session = runCli(cliPath, "import", "test-import.txt", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "first", "second", "plain task"}))
+		Expect(session).Should(gbytes.Say(`\+ first\n\+ second\n\+ plain task\nx done task\n`))
+	})
+
+	It("maps CSV columns with flags", func() {
+		writeImportFile("test-import.csv", "Title,When,Status\nnow task,,\nlater task,x,\n")
+		session = runCli(cliPath, "import", "test-import.csv", "--task-column", "title", "--later-column", "2", "--completed-column", "Status", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "now task"}))
+		Expect(list.DeferredTodos()).To(Equal([]string{"later task"}))
+	})
+
+	It("only shows the todos with --dry-run", func() {
+		writeImportFile("test-import.md", "- [ ] wash car\n")
+		session = runCli(cliPath, "import", "test-import.md", "--dry-run", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`\+ wash car\nWould import 1 todos\n`))
+		Expect(list.Todos()).To(Equal([]string{"firstTask"}))
+	})
+
+	It("fails on an unknown format", func() {
+		writeImportFile("test-import.md", "- [ ] wash car\n")
+		session = runFailingImport("test-import.md", "--format", "xml")
+		Expect(session.Err).Should(gbytes.Say(`unknown import format \\"xml\\"`))
+		Expect(list.Todos()).To(Equal([]string{"firstTask"}))
+	})
+
+	It("fails on a file it cannot parse", func() {
+		writeImportFile("test-import.csv", "Title\nsome task\n")
+		session = runFailingImport("test-import.csv", "--task-column", "Name")
+		Expect(session.Err).Should(gbytes.Say("parsing import file"))
+		Expect(list.Todos()).To(Equal([]string{"firstTask"}))
+	})
+
+	It("continues a failed import where it stopped", func() {
+		writeImportFile("test-import.md", "- [ ] one\n- [ ] two\n- [ ] three\n")
+		created := 0
+		server.RouteToHandler("POST", "/createHref", func(w http.ResponseWriter, r *http.Request) {
+			if created == 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			created++
+			list.create(&list.todos)(w, r)
+		})
+		session = runFailingImport("test-import.md")
+		Expect(session.Err).Should(gbytes.Say("import stopped"))
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "one", "two"}))
+		Expect("test-import.md.resume").To(BeAnExistingFile())
+
+		server.RouteToHandler("POST", "/createHref", list.create(&list.todos))
+		session = runCli(cliPath, "import", "test-import.md", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "one", "two", "three"}))
+		Expect(session).Should(gbytes.Say(`\+ three\nImported 1 todos \(2 imported before\)\n`))
+		Expect("test-import.md.resume").NotTo(BeAnExistingFile())
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.Remove("./test-import.md")
+		os.Remove("./test-import.md.resume")
+		os.Remove("./test-import.txt")
+		os.Remove("./test-import.csv")
+		server.Close()
+	})
+})
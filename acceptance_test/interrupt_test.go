@@ -0,0 +1,80 @@
+package acceptance_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("interrupt", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var release chan struct{}
+
+	start := func(input io.Reader) {
+		command := exec.Command(cliPath, "login", "--api", server.URL(), "--config", "test-config.yml")
+		command.Stdin = input
+		var err error
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		release = make(chan struct{})
+		links := make(map[string]cmd.Link)
+		links["login"] = cmd.Link{Href: server.URL() + "/loginHref"}
+		server.RouteToHandler("GET", "/v1/", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.RouteToHandler("POST", "/loginHref", func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			links := make(map[string]cmd.Link)
+			links["root"] = cmd.Link{Href: "rootResourcesHref"}
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.SessionResponse{
+				Session: cmd.Session{Token: "someToken"},
+				Links:   links,
+			})(w, r)
+		})
+	})
+
+	It("cancels a request in flight and exits with status 130 without saving the session", func() {
+		start(gbytes.BufferWithBytes([]byte("someEmail\nsomePassword\n")))
+		Eventually(func() int { return len(server.ReceivedRequests()) }).Should(Equal(2))
+		session.Interrupt()
+		Eventually(session, 5).Should(gexec.Exit(130))
+		config, err := ioutil.ReadFile("test-config.yml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(config)).NotTo(ContainSubstring("someToken"))
+	})
+
+	It("exits with status 130 when interrupted at a prompt", func() {
+		reader, writer, err := os.Pipe()
+		Expect(err).NotTo(HaveOccurred())
+		defer reader.Close()
+		defer writer.Close()
+		start(reader)
+		Eventually(session).Should(gbytes.Say("Email"))
+		session.Interrupt()
+		Eventually(session, 5).Should(gexec.Exit(130))
+	})
+
+	AfterEach(func() {
+		close(release)
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
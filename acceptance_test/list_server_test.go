@@ -0,0 +1,196 @@
+package acceptance_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+// listServer serves a single list whose todos change as the CLI follows the
+// links of the list and its todos. The list has an ETag that changes with
+// every change, and changes sent with an If-Match for an older version are
+// refused.
+type listServer struct {
+	server         *ghttp.Server
+	mu             sync.Mutex
+	todos          []string
+	deferredTodos  []string
+	completed      []cmd.CompletedTodo
+	version        int
+	beforeMutation func()
+}
+
+var todoActionPath = regexp.MustCompile(`^/todos/(now|later)/(\d+)/(complete|move|update|delete)$`)
+
+func newListServer(server *ghttp.Server, todos ...string) *listServer {
+	list := &listServer{server: server, todos: todos, deferredTodos: []string{}}
+	writeSessionConfig(server.URL() + "/rootResourcesHref")
+	links := make(map[string]cmd.Link)
+	links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+	server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+	server.RouteToHandler("GET", "/listHref", func(w http.ResponseWriter, r *http.Request) {
+		response, etag := list.response()
+		ghttp.RespondWithJSONEncoded(http.StatusOK, response, http.Header{"ETag": []string{etag}})(w, r)
+	})
+	server.RouteToHandler("GET", "/completedHref", func(w http.ResponseWriter, r *http.Request) {
+		list.mu.Lock()
+		defer list.mu.Unlock()
+		completed := append([]cmd.CompletedTodo{}, list.completed...)
+		ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.CompletedListResponse{List: cmd.CompletedList{Todos: completed}})(w, r)
+	})
+	server.RouteToHandler("POST", "/createHref", list.create(&list.todos))
+	server.RouteToHandler("POST", "/createDeferredHref", list.create(&list.deferredTodos))
+	server.RouteToHandler("POST", todoActionPath, list.todoAction)
+	server.RouteToHandler("PUT", todoActionPath, list.todoAction)
+	server.RouteToHandler("DELETE", todoActionPath, list.todoAction)
+	return list
+}
+
+// todoAction changes a todo through one of the links the todo resources
+// carry.
+func (list *listServer) todoAction(w http.ResponseWriter, r *http.Request) {
+	if !list.mutate(w, r) {
+		return
+	}
+	defer list.mu.Unlock()
+	match := todoActionPath.FindStringSubmatch(r.URL.Path)
+	todos := &list.todos
+	if match[1] == "later" {
+		todos = &list.deferredTodos
+	}
+	index, _ := strconv.Atoi(match[2])
+	task := (*todos)[index]
+	if match[3] == "update" {
+		var form struct {
+			Task string `json:"task"`
+		}
+		Expect(json.NewDecoder(r.Body).Decode(&form)).To(Succeed())
+		(*todos)[index] = form.Task
+		return
+	}
+	*todos = append((*todos)[:index], (*todos)[index+1:]...)
+	if match[3] == "move" {
+		var form struct {
+			Position int `json:"position"`
+		}
+		Expect(json.NewDecoder(r.Body).Decode(&form)).To(Succeed())
+		position := form.Position - 1
+		*todos = append((*todos)[:position], append([]string{task}, (*todos)[position:]...)...)
+	} else if match[3] == "complete" {
+		list.completed = append(list.completed, cmd.CompletedTodo{Task: task, CompletedAt: time.Now().UTC()})
+	}
+}
+
+func (list *listServer) create(todos *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !list.mutate(w, r) {
+			return
+		}
+		defer list.mu.Unlock()
+		var form struct {
+			Task string `json:"task"`
+		}
+		Expect(json.NewDecoder(r.Body).Decode(&form)).To(Succeed())
+		*todos = append(*todos, form.Task)
+	}
+}
+
+// mutate locks the list for a change and moves it to a new version, unless
+// the request is for an older version, which it refuses.
+func (list *listServer) mutate(w http.ResponseWriter, r *http.Request) bool {
+	list.mu.Lock()
+	if list.beforeMutation != nil {
+		hook := list.beforeMutation
+		list.beforeMutation = nil
+		hook()
+	}
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && ifMatch != list.etag() {
+		list.mu.Unlock()
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return false
+	}
+	list.version++
+	return true
+}
+
+func (list *listServer) etag() string {
+	return fmt.Sprintf(`"v%d"`, list.version)
+}
+
+func (list *listServer) response() (cmd.ListResponse, string) {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	links := make(map[string]cmd.Link)
+	links["create"] = cmd.Link{Href: list.server.URL() + "/createHref"}
+	links["createDeferred"] = cmd.Link{Href: list.server.URL() + "/createDeferredHref"}
+	links["completed"] = cmd.Link{Href: list.server.URL() + "/completedHref"}
+	return cmd.ListResponse{List: cmd.List{
+		Name:          "now",
+		DeferredName:  "later",
+		Todos:         list.todoResources("now", list.todos),
+		DeferredTodos: list.todoResources("later", list.deferredTodos),
+		Links:         links,
+	}}, list.etag()
+}
+
+func (list *listServer) todoResources(section string, tasks []string) []cmd.Todo {
+	todos := make([]cmd.Todo, 0, len(tasks))
+	for i, task := range tasks {
+		links := make(map[string]cmd.Link)
+		links["complete"] = cmd.Link{Href: fmt.Sprintf("%s/todos/%s/%d/complete", list.server.URL(), section, i)}
+		links["move"] = cmd.Link{Href: fmt.Sprintf("%s/todos/%s/%d/move", list.server.URL(), section, i)}
+		links["update"] = cmd.Link{Href: fmt.Sprintf("%s/todos/%s/%d/update", list.server.URL(), section, i)}
+		links["delete"] = cmd.Link{Href: fmt.Sprintf("%s/todos/%s/%d/delete", list.server.URL(), section, i)}
+		todos = append(todos, cmd.Todo{Task: task, Links: links})
+	}
+	return todos
+}
+
+// Todos returns the tasks of the now list.
+func (list *listServer) Todos() []string {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	return append([]string{}, list.todos...)
+}
+
+// DeferredTodos returns the tasks of the later list.
+func (list *listServer) DeferredTodos() []string {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	return append([]string{}, list.deferredTodos...)
+}
+
+// SetCompleted replaces the completed history.
+func (list *listServer) SetCompleted(todos ...cmd.CompletedTodo) {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	list.completed = todos
+}
+
+// SetTodos replaces the tasks of the now list, as another client would.
+func (list *listServer) SetTodos(tasks ...string) {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	list.todos = tasks
+	list.version++
+}
+
+// SetTodosBeforeNextChange replaces the tasks of the now list just before
+// the next change the CLI sends, as another client racing it would.
+func (list *listServer) SetTodosBeforeNextChange(tasks ...string) {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	list.beforeMutation = func() {
+		list.todos = tasks
+		list.version++
+	}
+}
@@ -0,0 +1,51 @@
+package acceptance_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("list", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var serverURL string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		serverURL = server.URL()
+		list := newListServer(server, "firstTask", "secondTask")
+		list.deferredTodos = []string{"laterTask"}
+	})
+
+	It("shows the numbered todos of the now and later lists", func() {
+		session = runCli(cliPath, "list", "--api", serverURL, "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("now:\n  1. firstTask\n  2. secondTask\nlater:\n  1. laterTask\n"))
+	})
+
+	It("shows the offline copy when the server cannot be reached", func() {
+		runCli(cliPath, "list", "--api", serverURL, "--config", "test-config.yml")
+		server.Close()
+		session = runCli(cliPath, "list", "--retries", "0", "--api", serverURL, "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("offline copy from"))
+		Expect(session).Should(gbytes.Say("  1. firstTask\n  2. secondTask\n"))
+		Expect(session.Err).Should(gbytes.Say("server unreachable"))
+	})
+
+	It("reports when there is no offline copy", func() {
+		session = runCli(cliPath, "list", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say("no offline copy of the list"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
@@ -0,0 +1,97 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("lists", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/defaultListHref"}
+		links["lists"] = cmd.Link{Href: server.URL() + "/listsHref"}
+		listsLinks := make(map[string]cmd.Link)
+		listsLinks["create"] = cmd.Link{Href: server.URL() + "/createListHref"}
+		projectLinks := make(map[string]cmd.Link)
+		projectLinks["list"] = cmd.Link{Href: server.URL() + "/projectListHref"}
+		projectLinks["rename"] = cmd.Link{Href: server.URL() + "/renameProjectHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.RouteToHandler("GET", "/listsHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListsResponse{
+			Lists: []cmd.ListSummary{
+				{Name: "default"},
+				{Name: "project-x", Links: projectLinks},
+			},
+			Links: listsLinks,
+		}))
+	})
+
+	It("shows the names of all lists", func() {
+		session = runCli(cliPath, "lists", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("default"))
+		Expect(session).Should(gbytes.Say("project-x"))
+	})
+
+	It("creates a list through the create link", func() {
+		server.RouteToHandler("POST", "/createListHref", ghttp.CombineHandlers(
+			ghttp.VerifyHeaderKV("Session-Token", "someToken"),
+			ghttp.VerifyJSON(`{"name":"project-y"}`),
+		))
+		session = runCli(cliPath, "lists", "create", "project-y", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(server.ReceivedRequests()).Should(HaveLen(3))
+		Expect(session).Should(gbytes.Say(`Created list "project-y"`))
+	})
+
+	It("renames a list through its rename link", func() {
+		server.RouteToHandler("PUT", "/renameProjectHref", ghttp.VerifyJSON(`{"name":"project-z"}`))
+		session = runCli(cliPath, "lists", "rename", "project-x", "project-z", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(server.ReceivedRequests()).Should(HaveLen(3))
+		Expect(session).Should(gbytes.Say(`Renamed list "project-x" to "project-z"`))
+	})
+
+	It("keeps the current list when the rename is refused", func() {
+		server.RouteToHandler("PUT", "/renameProjectHref", ghttp.RespondWith(http.StatusConflict, nil))
+		runCli(cliPath, "lists", "use", "project-x", "--api", server.URL(), "--config", "test-config.yml")
+		session = runCli(cliPath, "lists", "rename", "project-x", "project-z", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say("request failed"))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring("Renamed"))
+		contents, _ := ioutil.ReadFile("test-config.yml")
+		Expect(string(contents)).To(ContainSubstring("current-list: project-x"))
+	})
+
+	It("stores the list chosen with use in the config file", func() {
+		session = runCli(cliPath, "lists", "use", "project-x", "--api", server.URL(), "--config", "test-config.yml")
+		contents, _ := ioutil.ReadFile("test-config.yml")
+		Expect(string(contents)).To(ContainSubstring("current-list: project-x"))
+		session = runCli(cliPath, "lists", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`\* project-x`))
+	})
+
+	It("works with the list chosen with --list", func() {
+		server.RouteToHandler("GET", "/projectListHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{
+			List: cmd.List{DeferredName: "later", UnlockDuration: 60000},
+		}))
+		session = runCli(cliPath, "unlock", "--list", "project-x", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("later list: unlocked, 1m0s remaining"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
@@ -0,0 +1,65 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("logging", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.RouteToHandler("GET", "/listHref", ghttp.RespondWith(http.StatusOK, "not json"))
+	})
+
+	It("keeps diagnostics out of stdout", func() {
+		session = runCli(cliPath, "unlock", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+		Expect(session.Err).Should(gbytes.Say("level=ERROR msg=\"decoding response\""))
+	})
+
+	It("logs informational messages with -v and debug messages with -vv", func() {
+		session = runCli(cliPath, "unlock", "-v", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say("level=INFO msg=\"using config file\""))
+		Expect(session.Err.Contents()).ShouldNot(ContainSubstring("level=DEBUG"))
+		session = runCli(cliPath, "unlock", "-vv", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say("level=DEBUG msg=request method=GET"))
+	})
+
+	It("only logs errors with -q", func() {
+		session = runCli(cliPath, "unlock", "-q", "-vv", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err.Contents()).ShouldNot(ContainSubstring("level=DEBUG"))
+		Expect(session.Err).Should(gbytes.Say("level=ERROR"))
+	})
+
+	It("logs as json to a file", func() {
+		session = runCli(cliPath, "unlock", "--log-format", "json", "--log-file", "test.log", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err.Contents()).To(BeEmpty())
+		contents, err := ioutil.ReadFile("test.log")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring(`"level":"ERROR","msg":"decoding response"`))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.Remove("./test.log")
+		server.Close()
+	})
+})
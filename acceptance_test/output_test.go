@@ -0,0 +1,83 @@
+package acceptance_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("output", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["completedList"] = cmd.Link{Href: server.URL() + "/completedListHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.RouteToHandler("GET", "/completedListHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.CompletedListResponse{
+			List: cmd.CompletedList{Todos: []cmd.CompletedTodo{
+				{Task: "Write\treport", CompletedAt: time.Date(2019, 5, 2, 12, 0, 0, 0, time.UTC)},
+			}},
+		}))
+	})
+
+	It("renders results as json", func() {
+		session = runCli(cliPath, "completed", "--output", "json", "--api", server.URL(), "--config", "test-config.yml")
+		var result cmd.CompletedTodosResult
+		Expect(json.Unmarshal(session.Out.Contents(), &result)).To(Succeed())
+		Expect(result.Todos).To(HaveLen(1))
+		Expect(result.Todos[0].Task).To(Equal("Write\treport"))
+	})
+
+	It("renders results as yaml", func() {
+		session = runCli(cliPath, "completed", "-o", "yaml", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("todos:"))
+		Expect(session).Should(gbytes.Say("completedAt: \"2019-05-02T12:00:00Z\""))
+	})
+
+	It("renders results as an aligned table with a header", func() {
+		session = runCli(cliPath, "completed", "-o", "table", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`COMPLETEDAT\s+TASK`))
+		Expect(session).Should(gbytes.Say(`2019-05-02T12:00:00Z\s+Write`))
+	})
+
+	It("renders results as tab separated values with escaped fields", func() {
+		session = runCli(cliPath, "completed", "-o", "tsv", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("completedAt\ttask\n2019-05-02T12:00:00Z\tWrite\\treport\n"))
+	})
+
+	It("renders the action choice as json", func() {
+		session = runCli(cliPath, "-o", "json", "--api", server.URL(), "--config", "test-config.yml")
+		var result cmd.ActionsResult
+		decoder := json.NewDecoder(bytes.NewReader(session.Out.Contents()))
+		Expect(decoder.Decode(&result)).To(Succeed())
+		Expect(result.Actions).To(Equal([]string{"completedList"}))
+	})
+
+	It("rejects unknown formats", func() {
+		session, err := gexec.Start(exec.Command(cliPath, "completed", "-o", "xml", "--api", server.URL(), "--config", "test-config.yml"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(1))
+		Expect(session.Err).Should(gbytes.Say(`unknown output format \\"xml\\"`))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
@@ -0,0 +1,104 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("proxy and unix socket transports", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	respondWithLockedList := func(baseURL string) {
+		writeSessionConfig(baseURL + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: baseURL + "/listHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.CombineHandlers(
+			ghttp.VerifyHeaderKV("Session-Token", "someToken"),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}),
+		))
+		server.RouteToHandler("GET", "/listHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later"}}))
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+	})
+
+	Context("through a proxy", func() {
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			respondWithLockedList("http://doer.test")
+		})
+
+		It("uses the proxy named by HTTP_PROXY", func() {
+			session = runCliWithEnv(cliPath, []string{"HTTP_PROXY=" + server.URL(), "NO_PROXY="}, "unlock", "--retries", "0", "--api", "http://doer.test", "--config", "test-config.yml")
+			Expect(server.ReceivedRequests()).Should(HaveLen(2))
+			Expect(server.ReceivedRequests()[0].Host).To(Equal("doer.test"))
+			Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+		})
+
+		It("bypasses the proxy for hosts in NO_PROXY", func() {
+			session = runCliWithEnv(cliPath, []string{"HTTP_PROXY=" + server.URL(), "NO_PROXY=doer.test"}, "unlock", "--retries", "0", "--connect-timeout", "1s", "--api", "http://doer.test", "--config", "test-config.yml")
+			Expect(server.ReceivedRequests()).Should(BeEmpty())
+		})
+
+		It("prefers the --proxy flag to the environment", func() {
+			session = runCliWithEnv(cliPath, []string{"HTTP_PROXY=http://127.0.0.1:1", "NO_PROXY="}, "unlock", "--retries", "0", "--proxy", server.URL(), "--api", "http://doer.test", "--config", "test-config.yml")
+			Expect(server.ReceivedRequests()).Should(HaveLen(2))
+			Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+		})
+	})
+
+	Context("over a unix socket", func() {
+		var socketDir string
+
+		BeforeEach(func() {
+			var err error
+			socketDir, err = ioutil.TempDir("", "doer-cli")
+			Expect(err).NotTo(HaveOccurred())
+			listener, err := net.Listen("unix", filepath.Join(socketDir, "doer.sock"))
+			Expect(err).NotTo(HaveOccurred())
+			server = ghttp.NewUnstartedServer()
+			server.HTTPTestServer.Listener.Close()
+			server.HTTPTestServer.Listener = listener
+			server.Start()
+		})
+
+		It("sends every request to the socket", func() {
+			links := make(map[string]cmd.Link)
+			links["login"] = cmd.Link{Href: "http://unix/loginHref"}
+			links["signup"] = cmd.Link{Href: "http://unix/signupHref"}
+			server.RouteToHandler("GET", "/v1/", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+			session = runCli(cliPath, "--api", "unix://"+filepath.Join(socketDir, "doer.sock"), "--config", "test-config.yml")
+			Expect(server.ReceivedRequests()).Should(HaveLen(1))
+			Expect(string(session.Out.Contents())).To(Equal("Choose action [login signup]: Chosen selection has not yet been implemented\n"))
+		})
+
+		It("follows links from resources fetched over the socket", func() {
+			respondWithLockedList("http://unix")
+			session = runCli(cliPath, "unlock", "--api", "unix://"+filepath.Join(socketDir, "doer.sock"), "--config", "test-config.yml")
+			Expect(server.ReceivedRequests()).Should(HaveLen(2))
+			Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(socketDir)
+		})
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
@@ -0,0 +1,58 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("redo", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		listLinks := make(map[string]cmd.Link)
+		listLinks["redo"] = cmd.Link{Href: server.URL() + "/redoHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.AppendHandlers(
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{
+				Name:          "now",
+				DeferredName:  "later",
+				DeferredTodos: []cmd.Todo{{Task: "movedTask"}},
+				Links:         listLinks,
+			}}),
+			ghttp.VerifyRequest("POST", "/redoHref"),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{
+				Name:         "now",
+				DeferredName: "later",
+				Todos:        []cmd.Todo{{Task: "movedTask"}},
+			}}),
+		)
+	})
+
+	It("follows the redo link and shows the difference it made", func() {
+		session = runCli(cliPath, "redo", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("now:"))
+		Expect(session).Should(gbytes.Say(`\+ movedTask`))
+		Expect(session).Should(gbytes.Say("later:"))
+		Expect(session).Should(gbytes.Say("- movedTask"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
@@ -98,6 +98,43 @@ var _ = Describe("root", func() {
 			Expect(session).Should(gbytes.Say("rootResource2"))
 			Expect(session).ShouldNot(gbytes.Say("self"))
 		})
+
+		It("shows whether the later list is locked", func() {
+			links := make(map[string]cmd.Link)
+			links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/rootResourcesHref"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/listHref"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later"}}),
+				),
+			)
+			session = runCli(cliPath, "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session).Should(gbytes.Say("later list: locked"))
+			Expect(session).Should(gbytes.Say("Choose action"))
+		})
+
+		It("leaves out the lock status when the list cannot be fetched", func() {
+			links := make(map[string]cmd.Link)
+			links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/rootResourcesHref"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/listHref"),
+					ghttp.RespondWith(http.StatusForbidden, nil),
+				),
+			)
+			session = runCli(cliPath, "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session).Should(gbytes.Say("Choose action"))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("later list"))
+			Expect(session.Err).Should(gbytes.Say("request failed"))
+		})
 	})
 
 	AfterEach(func() {
@@ -0,0 +1,85 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("scan", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var list *listServer
+
+	writeSource := func(path string, contents string) {
+		path = filepath.Join("test-scan", path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		list = newListServer(server)
+		Expect(os.MkdirAll("test-scan/.git", 0700)).To(Succeed())
+		writeSource(".gitignore", "build/\n*.gen.go\n!keep.gen.go\n")
+		writeSource(".git/hooks.go", "// TODO: not scanned\n")
+		writeSource("build/out.go", "// TODO: ignored directory\n")
+		writeSource("src/app.py", "x = 1  # TODO tidy up\n")
+		writeSource("src/main.go", "package main\n\n// TODO: handle errors\nfunc main() {}\n\n/* FIXME(bob): leaks\n */\n")
+		writeSource("src/notes.txt", "TODO: not a source file\n")
+		writeSource("src/skip.gen.go", "// TODO: ignored file\n")
+		writeSource("src/keep.gen.go", "// TODO: kept\n")
+	})
+
+	It("adds a todo for each comment in the files .gitignore does not exclude", func() {
+		session = runCli(cliPath, "scan", "test-scan", "--api", server.URL(), "--config", "test-config.yml")
+		todos := list.Todos()
+		Expect(todos).To(HaveLen(4))
+		Expect(todos[0]).To(MatchRegexp(`^TODO: tidy up \(test-scan/src/app.py:1\) \[[0-9a-f]{12}\]$`))
+		Expect(todos[1]).To(MatchRegexp(`^TODO: kept \(test-scan/src/keep.gen.go:1\) \[[0-9a-f]{12}\]$`))
+		Expect(todos[2]).To(MatchRegexp(`^TODO: handle errors \(test-scan/src/main.go:3\) \[[0-9a-f]{12}\]$`))
+		Expect(todos[3]).To(MatchRegexp(`^FIXME: leaks \(test-scan/src/main.go:6\) \[[0-9a-f]{12}\]$`))
+		Expect(session).Should(gbytes.Say("Added 4 todos, 0 already in the list"))
+	})
+
+	It("does not add comments again when they move", func() {
+		runCli(cliPath, "scan", "test-scan", "--api", server.URL(), "--config", "test-config.yml")
+		writeSource("src/main.go", "package main\n\nimport \"fmt\"\n\n// TODO: handle errors\nfunc main() {}\n\n/* FIXME(bob): leaks\n */\n// TODO: new one\n")
+		session = runCli(cliPath, "scan", "test-scan", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(list.Todos()).To(HaveLen(5))
+		Expect(list.Todos()[4]).To(HavePrefix("TODO: new one (test-scan/src/main.go:10)"))
+		Expect(session).Should(gbytes.Say("Added 1 todos, 4 already in the list"))
+	})
+
+	It("fingerprints comments the same wherever scan is run from", func() {
+		runCli(cliPath, "scan", "test-scan", "--api", server.URL(), "--config", "test-config.yml")
+		session = runCli(cliPath, "scan", "test-scan/src", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("Added 0 todos, 4 already in the list"))
+		absolute, err := filepath.Abs("test-scan")
+		Expect(err).NotTo(HaveOccurred())
+		session = runCli(cliPath, "scan", absolute, "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("Added 0 todos, 4 already in the list"))
+		Expect(list.Todos()).To(HaveLen(4))
+	})
+
+	It("only shows the todos with --dry-run", func() {
+		session = runCli(cliPath, "scan", "test-scan", "--dry-run", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("Would add 4 todos, 0 already in the list"))
+		Expect(list.Todos()).To(BeEmpty())
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.RemoveAll("./test-scan")
+		server.Close()
+	})
+})
@@ -0,0 +1,90 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("search", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var links map[string]cmd.Link
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links = make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		links["completedList"] = cmd.Link{Href: server.URL() + "/completedListHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", func(w http.ResponseWriter, r *http.Request) {
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links})(w, r)
+		})
+		server.RouteToHandler("GET", "/listHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{
+			Name:          "now",
+			DeferredName:  "later",
+			Todos:         []cmd.Todo{{Task: "Write report"}, {Task: "Call bob"}},
+			DeferredTodos: []cmd.Todo{{Task: "Review report draft"}},
+		}}))
+		server.RouteToHandler("GET", "/completedListHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.CompletedListResponse{
+			List: cmd.CompletedList{Todos: []cmd.CompletedTodo{{Task: "Send REPORT", CompletedAt: time.Now()}}},
+		}))
+	})
+
+	It("finds todos containing the query across the now, later and completed lists", func() {
+		session = runCli(cliPath, "search", "report", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`now\s+Write report`))
+		Expect(session).Should(gbytes.Say(`later\s+Review report draft`))
+		Expect(session.Out.Contents()).ShouldNot(ContainSubstring("Send REPORT"))
+	})
+
+	It("ignores case when asked to", func() {
+		session = runCli(cliPath, "search", "-i", "report", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`completed\s+Send REPORT`))
+	})
+
+	It("matches regular expressions", func() {
+		session = runCli(cliPath, "search", "--regex", "^(Call|Send) ", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`now\s+Call bob`))
+		Expect(session).Should(gbytes.Say(`completed\s+Send REPORT`))
+		Expect(session.Out.Contents()).ShouldNot(ContainSubstring("Write report"))
+	})
+
+	It("uses the search link when the server advertises one", func() {
+		links["search"] = cmd.Link{Href: server.URL() + "/search{?query,ignoreCase}", Templated: true}
+		server.RouteToHandler("GET", "/search", ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/search", "query=bob+smith"),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.SearchResponse{
+				Todos: []cmd.SearchResult{{Task: "Call bob smith", List: "now"}},
+			}),
+		))
+		session = runCli(cliPath, "search", "bob smith", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(server.ReceivedRequests()).Should(HaveLen(2))
+		Expect(session).Should(gbytes.Say(`now\s+Call bob smith`))
+	})
+
+	It("searches locally when the search link is not templated", func() {
+		links["search"] = cmd.Link{Href: server.URL() + "/search"}
+		server.RouteToHandler("GET", "/search", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.SearchResponse{
+			Todos: []cmd.SearchResult{{Task: "Unfiltered", List: "now"}},
+		}))
+		session = runCli(cliPath, "search", "report", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`now\s+Write report`))
+		Expect(session.Out.Contents()).ShouldNot(ContainSubstring("Unfiltered"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
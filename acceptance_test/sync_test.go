@@ -0,0 +1,109 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("sync", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var serverURL string
+	var list *listServer
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		serverURL = server.URL()
+		list = newListServer(server, "firstTask", "secondTask")
+		runCli(cliPath, "list", "--api", serverURL, "--config", "test-config.yml")
+	})
+
+	It("queues changes made offline and applies them to the offline copy", func() {
+		session = runCli(cliPath, "add", "offlineTask", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say(`\+ offlineTask`))
+		Expect(session.Err).Should(gbytes.Say("queued until the next sync"))
+		session = runCli(cliPath, "complete", "1", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		session = runCli(cliPath, "list", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		Expect(session).Should(gbytes.Say("  1. secondTask\n  2. offlineTask\n"))
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "secondTask"}))
+	})
+
+	It("works offline when the server cannot be reached", func() {
+		server.Close()
+		session = runCli(cliPath, "add", "offlineTask", "--retries", "0", "--api", serverURL, "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say("server unreachable, working offline"))
+		Expect(session).Should(gbytes.Say(`\+ offlineTask`))
+	})
+
+	It("replays queued changes in order once the server is reachable", func() {
+		runCli(cliPath, "add", "offlineTask", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		runCli(cliPath, "move", "3", "1", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		runCli(cliPath, "complete", "3", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		session = runCli(cliPath, "sync", "--api", serverURL, "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("applied add \"offlineTask\"\napplied move \"offlineTask\" to 1\napplied complete \"secondTask\"\n"))
+		Expect(list.Todos()).To(Equal([]string{"offlineTask", "firstTask"}))
+		session = runCli(cliPath, "sync", "--api", serverURL, "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("Nothing to sync\n"))
+	})
+
+	It("reports and drops changes that no longer apply", func() {
+		runCli(cliPath, "complete", "1", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		list.SetTodos("secondTask")
+		session = runCli(cliPath, "sync", "--api", serverURL, "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("skipped complete \"firstTask\": no todo \"firstTask\" in the now list\n"))
+		session = runCli(cliPath, "sync", "--api", serverURL, "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("Nothing to sync\n"))
+	})
+
+	It("keeps queued changes while the server cannot be reached", func() {
+		runCli(cliPath, "add", "offlineTask", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		server.Close()
+		session = runCli(cliPath, "sync", "--retries", "0", "--api", serverURL, "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("pending add \"offlineTask\"\n"))
+	})
+
+	It("keeps queued changes when the server refuses them", func() {
+		runCli(cliPath, "add", "offlineTask", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		server.RouteToHandler("GET", "/listHref", ghttp.RespondWith(http.StatusUnauthorized, nil))
+		session = runCli(cliPath, "sync", "--api", serverURL, "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("pending add \"offlineTask\"\n"))
+		Expect(session.Err).Should(gbytes.Say("stopping sync"))
+
+		newListServer(server, "firstTask", "secondTask")
+		session = runCli(cliPath, "sync", "--api", serverURL, "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("applied add \"offlineTask\"\n"))
+	})
+
+	It("queues changes after a line cut short by a crash", func() {
+		runCli(cliPath, "add", "offlineTask", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		queue, err := os.OpenFile(".doer-cli/queue.jsonl", os.O_WRONLY|os.O_APPEND, 0600)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = queue.WriteString(`{"action":"add","task":"cut`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queue.Close()).To(Succeed())
+		runCli(cliPath, "add", "anotherTask", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		session = runCli(cliPath, "sync", "--api", serverURL, "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("applied add \"offlineTask\"\napplied add \"anotherTask\"\n"))
+	})
+
+	It("queues changes behind ones that are waiting to be synced", func() {
+		runCli(cliPath, "add", "offlineTask", "--offline", "--api", serverURL, "--config", "test-config.yml")
+		session = runCli(cliPath, "add", "anotherTask", "--api", serverURL, "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say("queued until the next sync"))
+		Expect(list.Todos()).To(Equal([]string{"firstTask", "secondTask"}))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
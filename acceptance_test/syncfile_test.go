@@ -0,0 +1,116 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("sync-file", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var list *listServer
+
+	writeChecklist := func(contents string) {
+		Expect(ioutil.WriteFile("test-todo.md", []byte(contents), 0644)).To(Succeed())
+	}
+
+	readChecklist := func() string {
+		contents, err := ioutil.ReadFile("test-todo.md")
+		Expect(err).NotTo(HaveOccurred())
+		return string(contents)
+	}
+
+	syncFile := func() {
+		session = runCli(cliPath, "sync-file", "test-todo.md", "--api", server.URL(), "--config", "test-config.yml")
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		list = newListServer(server, "serverTask")
+		writeChecklist("# Project\n\n- [ ] fileTask\n\nNotes stay put.\n")
+		syncFile()
+	})
+
+	It("adds the todos of each side to the other", func() {
+		Expect(list.Todos()).To(Equal([]string{"serverTask", "fileTask"}))
+		Expect(readChecklist()).To(Equal("# Project\n\n- [ ] fileTask\n- [ ] serverTask\n\nNotes stay put.\n"))
+		Expect(session).Should(gbytes.Say("list: add \"fileTask\"\nfile: add \"serverTask\"\n"))
+	})
+
+	It("changes nothing when run again", func() {
+		syncFile()
+		Expect(session).Should(gbytes.Say("test-todo.md is in sync"))
+		Expect(list.Todos()).To(Equal([]string{"serverTask", "fileTask"}))
+		Expect(readChecklist()).To(Equal("# Project\n\n- [ ] fileTask\n- [ ] serverTask\n\nNotes stay put.\n"))
+	})
+
+	It("completes the todos of checked boxes", func() {
+		writeChecklist("# Project\n\n- [x] fileTask\n- [ ] serverTask\n\nNotes stay put.\n")
+		syncFile()
+		Expect(list.Todos()).To(Equal([]string{"serverTask"}))
+		Expect(readChecklist()).To(ContainSubstring("- [x] fileTask\n"))
+	})
+
+	It("deletes the todos of deleted lines", func() {
+		writeChecklist("# Project\n\n- [ ] serverTask\n\nNotes stay put.\n")
+		syncFile()
+		Expect(list.Todos()).To(Equal([]string{"serverTask"}))
+	})
+
+	It("checks the boxes of todos completed on the server and removes deleted ones", func() {
+		list.SetTodos("fileTask")
+		list.SetCompleted(cmd.CompletedTodo{Task: "serverTask"})
+		syncFile()
+		Expect(readChecklist()).To(Equal("# Project\n\n- [ ] fileTask\n- [x] serverTask\n\nNotes stay put.\n"))
+
+		list.SetTodos()
+		syncFile()
+		Expect(readChecklist()).To(Equal("# Project\n\n- [x] serverTask\n\nNotes stay put.\n"))
+		Expect(list.Todos()).To(BeEmpty())
+	})
+
+	It("does not check boxes from the default list's history in another list's file", func() {
+		list.SetCompleted(cmd.CompletedTodo{Task: "doneTask"})
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		links["lists"] = cmd.Link{Href: server.URL() + "/listsHref"}
+		links["completedList"] = cmd.Link{Href: server.URL() + "/completedHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		projectLinks := map[string]cmd.Link{"list": {Href: server.URL() + "/projectHref"}}
+		server.RouteToHandler("GET", "/listsHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListsResponse{
+			Lists: []cmd.ListSummary{{Name: "project-x", Links: projectLinks}},
+		}))
+		server.RouteToHandler("GET", "/projectHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{}))
+		Expect(ioutil.WriteFile("test-project.md", []byte("- [ ] doneTask\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(".test-project.md.doer.json", []byte(`{"version":1,"list":"project-x","todos":["doneTask"]}`), 0644)).To(Succeed())
+		session = runCli(cliPath, "sync-file", "test-project.md", "--api", server.URL(), "--config", "test-config.yml")
+		contents, err := ioutil.ReadFile("test-project.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal(""))
+	})
+
+	It("keeps the file bound to its list", func() {
+		session = runCli(cliPath, "sync-file", "test-todo.md", "--list", "other", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say(`test-todo.md is bound to list \\"\\"`))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.Remove("./test-todo.md")
+		os.Remove("./.test-todo.md.doer.json")
+		os.Remove("./test-project.md")
+		os.Remove("./.test-project.md.doer.json")
+		server.Close()
+	})
+})
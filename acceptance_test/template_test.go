@@ -0,0 +1,75 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("template and jsonpath output", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["completedList"] = cmd.Link{Href: server.URL() + "/completedListHref"}
+		links["lists"] = cmd.Link{Href: server.URL() + "/listsHref"}
+		firstLinks := make(map[string]cmd.Link)
+		firstLinks["self"] = cmd.Link{Href: "http://doer/lists/1"}
+		secondLinks := make(map[string]cmd.Link)
+		secondLinks["self"] = cmd.Link{Href: "http://doer/lists/2"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.RouteToHandler("GET", "/completedListHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.CompletedListResponse{
+			List: cmd.CompletedList{Todos: []cmd.CompletedTodo{
+				{Task: "firstTask", CompletedAt: time.Now()},
+				{Task: "secondTask", CompletedAt: time.Now()},
+			}},
+		}))
+		server.RouteToHandler("GET", "/listsHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListsResponse{
+			Lists: []cmd.ListSummary{{Name: "first", Links: firstLinks}, {Name: "second", Links: secondLinks}},
+		}))
+	})
+
+	It("renders results with a Go template", func() {
+		session = runCli(cliPath, "completed", "--template", `{{range .Todos}}{{.Task}}{{"\n"}}{{end}}`, "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("firstTask\nsecondTask\n"))
+	})
+
+	It("renders results with a named template from the config directory", func() {
+		Expect(os.MkdirAll(filepath.Join(".doer-cli", "templates"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(".doer-cli", "templates", "count.tmpl"), []byte(`{{len .Todos}} done`), 0644)).To(Succeed())
+		session = runCli(cliPath, "completed", "--template-name", "count", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("2 done"))
+	})
+
+	It("prints the values selected by a JSONPath expression", func() {
+		session = runCli(cliPath, "lists", "--jsonpath", "$.._links.self.href", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("http://doer/lists/1\nhttp://doer/lists/2\n"))
+	})
+
+	It("supports indexes and wildcards in JSONPath expressions", func() {
+		session = runCli(cliPath, "lists", "--jsonpath", "$.lists[-1]['name']", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("second\n"))
+		session = runCli(cliPath, "lists", "--jsonpath", "$.lists[*].name", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(string(session.Out.Contents())).To(Equal("first\nsecond\n"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.RemoveAll("./.doer-cli")
+		server.Close()
+	})
+})
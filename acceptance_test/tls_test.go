@@ -0,0 +1,141 @@
+package acceptance_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("tls", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	respondWithLockedList := func() {
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.RouteToHandler("GET", "/listHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later"}}))
+	}
+
+	writeServerCertificate := func() {
+		certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.HTTPTestServer.Certificate().Raw})
+		Expect(ioutil.WriteFile("test-ca.pem", certificate, 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+	})
+
+	Context("with a server certificate from an unknown authority", func() {
+		BeforeEach(func() {
+			server = ghttp.NewTLSServer()
+			respondWithLockedList()
+		})
+
+		It("refuses to connect by default", func() {
+			session = runCli(cliPath, "unlock", "--retries", "0", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session.Err).Should(gbytes.Say("certificate"))
+			Expect(server.ReceivedRequests()).Should(BeEmpty())
+		})
+
+		It("reports the refused certificate instead of working offline", func() {
+			session = runCli(cliPath, "add", "someTask", "--retries", "0", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session.Err).Should(gbytes.Say("certificate"))
+			Expect(string(session.Err.Contents())).NotTo(ContainSubstring("working offline"))
+		})
+
+		It("trusts the authorities in the --cacert bundle", func() {
+			writeServerCertificate()
+			session = runCli(cliPath, "unlock", "--cacert", "test-ca.pem", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+		})
+
+		It("reads the CA bundle from the config file", func() {
+			writeServerCertificate()
+			config := "session-token: someToken\nroot-href: " + server.URL() + "/rootResourcesHref\ncacert: test-ca.pem\n"
+			Expect(ioutil.WriteFile("test-config.yml", []byte(config), 0644)).To(Succeed())
+			session = runCli(cliPath, "unlock", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+		})
+
+		It("connects without verification with --insecure-skip-verify and warns about it", func() {
+			session = runCli(cliPath, "unlock", "--insecure-skip-verify", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+			Expect(session.Err).Should(gbytes.Say("level=WARN msg=\"TLS CERTIFICATE VERIFICATION IS DISABLED"))
+		})
+	})
+
+	Context("with a server that requires client certificates", func() {
+		BeforeEach(func() {
+			clientCertificate, clientKey := generateCertificate()
+			Expect(ioutil.WriteFile("test-client.pem", clientCertificate, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile("test-client-key.pem", clientKey, 0600)).To(Succeed())
+			pool := x509.NewCertPool()
+			pool.AppendCertsFromPEM(clientCertificate)
+			server = ghttp.NewUnstartedServer()
+			server.HTTPTestServer.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+			server.HTTPTestServer.StartTLS()
+			respondWithLockedList()
+			writeServerCertificate()
+		})
+
+		It("presents the --cert and --key pair", func() {
+			session = runCli(cliPath, "unlock", "--cacert", "test-ca.pem", "--cert", "test-client.pem", "--key", "test-client-key.pem", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+		})
+
+		It("is rejected without a client certificate", func() {
+			session = runCli(cliPath, "unlock", "--retries", "0", "--cacert", "test-ca.pem", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(server.ReceivedRequests()).Should(BeEmpty())
+			Expect(session.Err).Should(gbytes.Say("request failed"))
+		})
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		os.Remove("./test-ca.pem")
+		os.Remove("./test-client.pem")
+		os.Remove("./test-client-key.pem")
+		server.Close()
+	})
+})
+
+func generateCertificate() ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "doer-cli test client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
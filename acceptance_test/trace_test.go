@@ -0,0 +1,69 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("trace", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+	})
+
+	It("dumps requests and responses with the session token redacted", func() {
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+		server.RouteToHandler("GET", "/listHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later"}}))
+		session = runCli(cliPath, "unlock", "--trace", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say(`> GET http://.*/rootResourcesHref`))
+		Expect(session.Err).Should(gbytes.Say(`> Session-Token: REDACTED`))
+		Expect(session.Err).Should(gbytes.Say(`< 200 OK \(\d+ms\)`))
+		Expect(session.Err).Should(gbytes.Say(`< Content-Type: application/json`))
+		Expect(session.Err).Should(gbytes.Say(`"deferredName":"later"`))
+		Expect(session.Err.Contents()).ShouldNot(ContainSubstring("someToken"))
+		Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+	})
+
+	It("redacts passwords and session tokens in login bodies", func() {
+		links := make(map[string]cmd.Link)
+		links["login"] = cmd.Link{Href: server.URL() + "/loginHref"}
+		rootLinks := make(map[string]cmd.Link)
+		rootLinks["root"] = cmd.Link{Href: server.URL() + "/rootResourcesHref"}
+		server.AppendHandlers(
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}),
+			ghttp.CombineHandlers(
+				ghttp.VerifyJSON(`{"email":"someEmail","password":"somePassword"}`),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.SessionResponse{Session: cmd.Session{Token: "secretToken"}, Links: rootLinks}),
+			),
+		)
+		input := gbytes.NewBuffer()
+		_, err := input.Write([]byte("someEmail\n" + "somePassword\n"))
+		Expect(err).NotTo(HaveOccurred())
+		session = runCliWithInput(cliPath, input, "login", "--trace", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say(`> POST http://.*/loginHref`))
+		Expect(session.Err).Should(gbytes.Say(`"password":"REDACTED"`))
+		Expect(session.Err).Should(gbytes.Say(`"token":"REDACTED"`))
+		Expect(session.Err.Contents()).ShouldNot(ContainSubstring("somePassword"))
+		Expect(session.Err.Contents()).ShouldNot(ContainSubstring("secretToken"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
@@ -0,0 +1,100 @@
+package acceptance_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("transport", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+	var rootResources http.HandlerFunc
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		links["lists"] = cmd.Link{Href: server.URL() + "/listsHref"}
+		rootResources = ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links})
+	})
+
+	It("retries GET requests the server fails until one succeeds", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusServiceUnavailable, ""),
+			ghttp.RespondWith(http.StatusBadGateway, ""),
+			rootResources,
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later"}}),
+		)
+		session = runCli(cliPath, "unlock", "--retry-backoff", "10ms", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(server.ReceivedRequests()).Should(HaveLen(4))
+		Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+	})
+
+	It("waits as long as Retry-After asks", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusTooManyRequests, "", http.Header{"Retry-After": []string{"1"}}),
+			rootResources,
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later"}}),
+		)
+		start := time.Now()
+		session, err := gexec.Start(exec.Command(cliPath, "unlock", "--retry-backoff", "10ms", "--api", server.URL(), "--config", "test-config.yml"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session, 5).Should(gexec.Exit(0))
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		Expect(string(session.Out.Contents())).To(Equal("later list: locked\n"))
+	})
+
+	It("gives up after the configured number of retries", func() {
+		Expect(ioutil.WriteFile("test-config.yml", []byte("session-token: someToken\nroot-href: "+server.URL()+"/rootResourcesHref\nretries: 1\n"), 0644)).To(Succeed())
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusServiceUnavailable, ""),
+			ghttp.RespondWith(http.StatusServiceUnavailable, ""),
+		)
+		server.SetAllowUnhandledRequests(true)
+		session = runCli(cliPath, "unlock", "--retry-backoff", "10ms", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(server.ReceivedRequests()).Should(HaveLen(2))
+	})
+
+	It("does not retry requests that are not idempotent", func() {
+		listsLinks := make(map[string]cmd.Link)
+		listsLinks["create"] = cmd.Link{Href: server.URL() + "/createListHref"}
+		server.AppendHandlers(
+			rootResources,
+			ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListsResponse{Links: listsLinks}),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/createListHref"),
+				ghttp.RespondWith(http.StatusServiceUnavailable, ""),
+			),
+		)
+		session = runCli(cliPath, "lists", "create", "project-x", "--retry-backoff", "10ms", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(server.ReceivedRequests()).Should(HaveLen(3))
+	})
+
+	It("times out when the server does not respond", func() {
+		server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(500 * time.Millisecond)
+		})
+		server.SetAllowUnhandledRequests(true)
+		session = runCli(cliPath, "unlock", "--read-timeout", "100ms", "--retries", "0", "--api", server.URL(), "--config", "test-config.yml")
+		Expect(session.Err).Should(gbytes.Say("request failed.*timeout awaiting response headers"))
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
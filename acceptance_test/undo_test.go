@@ -0,0 +1,77 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("undo", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+	})
+
+	When("the list advertises an undo link", func() {
+		BeforeEach(func() {
+			listLinks := make(map[string]cmd.Link)
+			listLinks["undo"] = cmd.Link{Href: server.URL() + "/undoHref"}
+			server.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{
+					Name:         "now",
+					DeferredName: "later",
+					Todos:        []cmd.Todo{{Task: "keptTask"}},
+					Links:        listLinks,
+				}}),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/undoHref"),
+					ghttp.VerifyHeaderKV("Session-Token", "someToken"),
+				),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{
+					Name:         "now",
+					DeferredName: "later",
+					Todos:        []cmd.Todo{{Task: "keptTask"}, {Task: "restoredTask"}},
+				}}),
+			)
+		})
+
+		It("follows the undo link and shows the difference it made", func() {
+			session = runCli(cliPath, "undo", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(server.ReceivedRequests()).Should(HaveLen(5))
+			Expect(session).Should(gbytes.Say("now:"))
+			Expect(session).Should(gbytes.Say("  keptTask"))
+			Expect(session).Should(gbytes.Say(`\+ restoredTask`))
+		})
+	})
+
+	When("the list does not advertise an undo link", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{}))
+		})
+
+		It("reports that there is nothing to undo", func() {
+			session = runCli(cliPath, "undo", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session).Should(gbytes.Say("Nothing to undo"))
+		})
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
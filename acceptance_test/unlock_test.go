@@ -0,0 +1,68 @@
+package acceptance_test
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/ctailor2/doer-cli/cmd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("unlock", func() {
+	var session *gexec.Session
+	var server *ghttp.Server
+	var cliPath string
+
+	BeforeEach(func() {
+		cliPath = buildCli()
+		server = ghttp.NewServer()
+		writeSessionConfig(server.URL() + "/rootResourcesHref")
+		links := make(map[string]cmd.Link)
+		links["list"] = cmd.Link{Href: server.URL() + "/listHref"}
+		server.RouteToHandler("GET", "/rootResourcesHref", ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ResourcesResponse{Links: links}))
+	})
+
+	When("the list advertises an unlock link", func() {
+		BeforeEach(func() {
+			listLinks := make(map[string]cmd.Link)
+			listLinks["unlock"] = cmd.Link{Href: server.URL() + "/unlockHref"}
+			server.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later", Links: listLinks}}),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/unlockHref"),
+					ghttp.VerifyHeaderKV("Session-Token", "someToken"),
+				),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later", UnlockDuration: 1800000}}),
+			)
+		})
+
+		It("unlocks the later list and reports when it locks again", func() {
+			session = runCli(cliPath, "unlock", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(server.ReceivedRequests()).Should(HaveLen(5))
+			Expect(session).Should(gbytes.Say(`later list unlocked for 30m0s \(until \d\d:\d\d\)`))
+		})
+	})
+
+	When("the list is already unlocked", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, cmd.ListResponse{List: cmd.List{DeferredName: "later", UnlockDuration: 90000}}),
+			)
+		})
+
+		It("reports how long remains", func() {
+			session = runCli(cliPath, "unlock", "--api", server.URL(), "--config", "test-config.yml")
+			Expect(session).Should(gbytes.Say("later list: unlocked, 1m30s remaining"))
+		})
+	})
+
+	AfterEach(func() {
+		gexec.CleanupBuildArtifacts()
+		os.Remove("./test-config.yml")
+		server.Close()
+	})
+})
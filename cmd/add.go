@@ -0,0 +1,46 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// addCmd represents the add command
+var addCmd = &cobra.Command{
+	Use:   "add <task>",
+	Short: "Add a todo to the list",
+	Long: `Add a todo to the end of the now list, or of the later list with --later,
+and show how the list changed. Offline, the todo is queued until sync.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		task := strings.Join(args, " ")
+		runOperation(ctx, func(list List) (QueuedOperation, error) {
+			return QueuedOperation{Action: "add", Later: laterTodos, Task: task}, nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+
+	addListFlag(addCmd)
+	addLaterFlag(addCmd)
+	addStrategyFlag(addCmd)
+}
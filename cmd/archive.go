@@ -0,0 +1,153 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// backupVersion is bumped whenever the layout of backup archives changes in
+// a way older versions of restore would misread.
+const backupVersion = 1
+
+const manifestName = "manifest.json"
+
+// Manifest describes a backup archive: a gzipped tar holding the manifest
+// and one export per list, each checked against its checksum on restore.
+type Manifest struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Server    string         `json:"server"`
+	Lists     []ManifestList `json:"lists"`
+}
+
+// ManifestList is the file a list was backed up to. The default list has no
+// name. CompletedMissing is set when the server offered no completed history
+// for the list, so none was backed up.
+type ManifestList struct {
+	Name             string `json:"name"`
+	File             string `json:"file"`
+	Size             int64  `json:"size"`
+	SHA256           string `json:"sha256"`
+	CompletedMissing bool   `json:"completedMissing,omitempty"`
+}
+
+func (entry ManifestList) displayName() string {
+	if entry.Name == "" {
+		return "default"
+	}
+	return entry.Name
+}
+
+func checksum(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeArchive writes the manifest and the files it lists, in that order, so
+// that a reader learns what to expect before anything else.
+func writeArchive(path string, manifest Manifest, files map[string][]byte) error {
+	manifestContents, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	var contents bytes.Buffer
+	compressed := gzip.NewWriter(&contents)
+	archive := tar.NewWriter(compressed)
+	add := func(name string, body []byte) error {
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(body)), ModTime: manifest.CreatedAt}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := archive.Write(body)
+		return err
+	}
+	if err := add(manifestName, manifestContents); err != nil {
+		return err
+	}
+	for _, entry := range manifest.Lists {
+		if err := add(entry.File, files[entry.File]); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	if err := compressed.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomically(path, contents.Bytes(), 0600)
+}
+
+// readArchive reads a backup archive and checks every file the manifest
+// lists against its size and checksum, so that nothing is restored from an
+// archive that is damaged or incomplete.
+func readArchive(path string) (Manifest, map[string][]byte, error) {
+	var manifest Manifest
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return manifest, nil, err
+	}
+	compressed, err := gzip.NewReader(bytes.NewReader(file))
+	if err != nil {
+		return manifest, nil, fmt.Errorf("not a backup archive: %v", err)
+	}
+	archive := tar.NewReader(compressed)
+	files := make(map[string][]byte)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return manifest, nil, fmt.Errorf("reading backup archive: %v", err)
+		}
+		contents, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return manifest, nil, fmt.Errorf("reading %s from backup archive: %v", header.Name, err)
+		}
+		files[header.Name] = contents
+	}
+	manifestContents, ok := files[manifestName]
+	if !ok {
+		return manifest, nil, fmt.Errorf("backup archive has no %s", manifestName)
+	}
+	if err := json.Unmarshal(manifestContents, &manifest); err != nil {
+		return manifest, nil, fmt.Errorf("reading %s: %v", manifestName, err)
+	}
+	if manifest.Version < 1 || manifest.Version > backupVersion {
+		return manifest, nil, fmt.Errorf("backup archive version %d is not supported, expected at most %d", manifest.Version, backupVersion)
+	}
+	for _, entry := range manifest.Lists {
+		contents, ok := files[entry.File]
+		if !ok {
+			return manifest, nil, fmt.Errorf("backup archive is missing %s", entry.File)
+		}
+		if int64(len(contents)) != entry.Size || checksum(contents) != entry.SHA256 {
+			return manifest, nil, fmt.Errorf("checksum mismatch for %s, the backup archive is damaged", entry.File)
+		}
+	}
+	return manifest, files, nil
+}
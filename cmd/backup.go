@@ -0,0 +1,119 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup <archive>",
+	Short: "Back up every list to an archive",
+	Long: `Back up the default list and every other list, with their now, later and
+completed todos, to a single archive. The archive holds a manifest with a
+checksum for each list and can be restored with restore, also to another
+server or account.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manifest, files, err := backupLists(ctx)
+		var notApplicableErr *notApplicableError
+		if errors.As(err, &notApplicableErr) {
+			return err
+		} else if err != nil {
+			return fmt.Errorf("request failed: %v", err)
+		}
+		if err := writeArchive(args[0], manifest, files); err != nil {
+			return fmt.Errorf("writing backup archive %s: %v", args[0], err)
+		}
+		render(MessageResult{Message: fmt.Sprintf("Backed up %d lists to %s", len(manifest.Lists), args[0])})
+		return nil
+	},
+}
+
+// backupLists walks from the root resources to the default list and every
+// list in the lists resource, exporting each in full. A list without a
+// completed history is backed up without one and marked in the manifest.
+func backupLists(ctx context.Context) (Manifest, map[string][]byte, error) {
+	manifest := Manifest{Version: backupVersion, CreatedAt: time.Now().UTC(), Server: serverUrl, Lists: make([]ManifestList, 0)}
+	files := make(map[string][]byte)
+	var rootResources ResourcesResponse
+	if err := fetchResource(ctx, "GET", Link{Href: viper.GetString("root-href")}, nil, &rootResources); err != nil {
+		return manifest, nil, err
+	}
+	include := map[string]bool{"now": true, "later": true, "completed": true}
+	add := func(name string, link Link, completed Link) error {
+		var listResponse ListResponse
+		if err := fetchResource(ctx, "GET", link, nil, &listResponse); err != nil {
+			return err
+		}
+		entry := ManifestList{Name: name}
+		if _, ok := listResponse.List.Links["completed"]; !ok && completed.Href == "" {
+			logger.Warn("list has no completed history, backing up its todos only", "list", entry.displayName())
+			entry.CompletedMissing = true
+		}
+		export, err := exportOf(ctx, listResponse.List, completed, include)
+		if err != nil {
+			return err
+		}
+		contents, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return err
+		}
+		entry.File = fmt.Sprintf("lists/%d.json", len(manifest.Lists))
+		entry.Size = int64(len(contents))
+		entry.SHA256 = checksum(contents)
+		files[entry.File] = contents
+		manifest.Lists = append(manifest.Lists, entry)
+		return nil
+	}
+	listLink, ok := rootResources.Links["list"]
+	if !ok {
+		return manifest, nil, notApplicable("no list to back up")
+	}
+	if err := add("", listLink, rootResources.Links["completedList"]); err != nil {
+		return manifest, nil, err
+	}
+	if link, ok := rootResources.Links["lists"]; ok {
+		var listsResponse ListsResponse
+		if err := fetchResource(ctx, "GET", link, nil, &listsResponse); err != nil {
+			return manifest, nil, err
+		}
+		for _, summary := range listsResponse.Lists {
+			// The lists resource may include the default list, which is
+			// already backed up.
+			if summary.Links["list"].Href == listLink.Href {
+				continue
+			}
+			if err := add(summary.Name, summary.Links["list"], Link{}); err != nil {
+				return manifest, nil, err
+			}
+		}
+	}
+	return manifest, files, nil
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+}
@@ -0,0 +1,34 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the HTTP response cache",
+	Long: `Responses from the server are cached next to the config file and
+revalidated with the server using their ETag and Last-Modified values, as
+their Cache-Control headers allow. Pass --no-cache to any command to bypass
+the cache, or use the subcommands to manage it.`,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+}
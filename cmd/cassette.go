@@ -0,0 +1,246 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+const cassetteVersion = 1
+
+const (
+	matchMethodURL     = "method-url"
+	matchMethodURLBody = "method-url-body"
+)
+
+var (
+	recordFile    string
+	replayFile    string
+	cassetteMatch string
+)
+
+// cassette is a recorded session with the server. URLs are matched by path
+// and query only, so a cassette replays whatever host it was recorded from.
+type cassette struct {
+	Version      int           `json:"version"`
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// recordTransport saves every request and response it carries to a cassette,
+// with secrets scrubbed, rewriting the file after each one so an interrupted
+// session still leaves a usable recording.
+type recordTransport struct {
+	next     http.RoundTripper
+	path     string
+	mu       sync.Mutex
+	cassette cassette
+}
+
+func newRecordTransport(next http.RoundTripper, path string) *recordTransport {
+	return &recordTransport{
+		next:     next,
+		path:     path,
+		cassette: cassette{Version: cassetteVersion, Interactions: []interaction{}},
+	}
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.next.RoundTrip(req)
+	if err != nil {
+		return response, err
+	}
+	responseBody, err := readBody(&response.Body)
+	if err != nil {
+		return response, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, interaction{
+		Request: recordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: scrubHeader(req.Header),
+			Body:   string(redactBody(requestBody)),
+		},
+		Response: recordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     scrubHeader(response.Header),
+			Body:       string(redactBody(responseBody)),
+		},
+	})
+	if err := t.save(); err != nil {
+		logger.Error("writing cassette", "path", t.path, "error", err)
+	}
+	return response, nil
+}
+
+func (t *recordTransport) save() error {
+	contents, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(t.path, append(contents, '\n'), 0600)
+}
+
+// replayTransport answers requests from a cassette without touching the
+// network. Recorded interactions are used in order; once every match for a
+// request has been used, the last one is repeated.
+type replayTransport struct {
+	cassette cassette
+	match    string
+	mu       sync.Mutex
+	used     []bool
+}
+
+func newReplayTransport(path string, match string) (*replayTransport, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %v", err)
+	}
+	var recorded cassette
+	if err := json.Unmarshal(contents, &recorded); err != nil {
+		return nil, fmt.Errorf("reading cassette %s: %v", path, err)
+	}
+	if recorded.Version != cassetteVersion {
+		return nil, fmt.Errorf("unsupported cassette version %d in %s", recorded.Version, path)
+	}
+	return &replayTransport{
+		cassette: recorded,
+		match:    match,
+		used:     make([]bool, len(recorded.Interactions)),
+	}, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	body := string(redactBody(requestBody))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	found := -1
+	for i, recorded := range t.cassette.Interactions {
+		if !t.matches(recorded.Request, req, body) {
+			continue
+		}
+		found = i
+		if !t.used[i] {
+			break
+		}
+	}
+	if found < 0 {
+		return nil, &notRecordedError{method: req.Method, uri: req.URL.RequestURI()}
+	}
+	t.used[found] = true
+	recorded := t.cassette.Interactions[found].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// notRecordedError is returned for requests a cassette has no answer to.
+// Retrying them could never succeed.
+type notRecordedError struct {
+	method string
+	uri    string
+}
+
+func (err *notRecordedError) Error() string {
+	return fmt.Sprintf("no recorded response for %s %s", err.method, err.uri)
+}
+
+func (t *replayTransport) matches(recorded recordedRequest, req *http.Request, body string) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+	recordedURL, err := req.URL.Parse(recorded.URL)
+	if err != nil || recordedURL.RequestURI() != req.URL.RequestURI() {
+		return false
+	}
+	return t.match != matchMethodURLBody || recorded.Body == body
+}
+
+// scrubHeader copies a header with the values of secret headers replaced.
+func scrubHeader(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	for name, values := range scrubbed {
+		if !redactedHeaders[name] {
+			continue
+		}
+		for i := range values {
+			values[i] = redacted
+		}
+	}
+	return scrubbed
+}
+
+// newCassetteTransport replays from the --replay cassette in place of the
+// network, or wraps the network transport to fill the --record cassette.
+func newCassetteTransport(network func() (http.RoundTripper, error)) (http.RoundTripper, error) {
+	if cassetteMatch != matchMethodURL && cassetteMatch != matchMethodURLBody {
+		return nil, fmt.Errorf("unknown --replay-match %q, expected %s or %s", cassetteMatch, matchMethodURL, matchMethodURLBody)
+	}
+	if recordFile != "" && replayFile != "" {
+		return nil, fmt.Errorf("--record and --replay cannot be used together")
+	}
+	if replayFile != "" {
+		return newReplayTransport(replayFile, cassetteMatch)
+	}
+	transport, err := network()
+	if err != nil {
+		return nil, err
+	}
+	if recordFile != "" {
+		return newRecordTransport(transport, recordFile), nil
+	}
+	return transport, nil
+}
@@ -0,0 +1,228 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var (
+	trace              bool
+	connectTimeout     time.Duration
+	readTimeout        time.Duration
+	retries            int
+	retryBackoff       time.Duration
+	caCertFile         string
+	clientCertFile     string
+	clientKeyFile      string
+	insecureSkipVerify bool
+	proxyURL           string
+	sharedClient       = &http.Client{}
+)
+
+// unixSocketHost stands in for the host of requests sent over a Unix socket.
+const unixSocketHost = "unix"
+
+// apiBaseURL is the URL of the server, or a stand in http URL when the
+// server is reached through a unix:// socket.
+func apiBaseURL() string {
+	if socketPath() != "" {
+		return "http://" + unixSocketHost
+	}
+	return serverUrl
+}
+
+// socketPath is the path of the server's Unix socket when it has a unix://
+// URL, and empty otherwise.
+func socketPath() string {
+	if strings.HasPrefix(serverUrl, "unix://") {
+		return strings.TrimPrefix(serverUrl, "unix://")
+	}
+	return ""
+}
+
+// httpClient returns the client every request to the server is made with.
+func httpClient() *http.Client {
+	return sharedClient
+}
+
+// configureHTTPClient builds the shared client from the flags and config.
+func configureHTTPClient() error {
+	transport, err := newTransport()
+	if err != nil {
+		return err
+	}
+	sharedClient = &http.Client{Transport: transport}
+	return nil
+}
+
+// newTransport builds the transport chain: the cache wraps retries, so only
+// requests it cannot answer are sent, retries wrap tracing, so every attempt
+// is traced, and tracing wraps the cassette, if any, and the connection
+// handling.
+func newTransport() (http.RoundTripper, error) {
+	transport, err := newCassetteTransport(newNetworkTransport)
+	if err != nil {
+		return nil, err
+	}
+	if trace {
+		transport = &traceTransport{next: transport, out: os.Stderr}
+	}
+	transport = &retryTransport{
+		next:    transport,
+		retries: retries,
+		backoff: retryBackoff,
+	}
+	// A cassette has to see every request to record or replay it faithfully.
+	if noCache || recordFile != "" || replayFile != "" {
+		return transport, nil
+	}
+	return &cacheTransport{
+		next:  transport,
+		dir:   cacheDir(),
+		token: func() string { return viper.GetString("session-token") },
+		now:   time.Now,
+	}, nil
+}
+
+// newNetworkTransport connects to the server with the configured timeouts,
+// proxy and TLS settings.
+func newNetworkTransport() (http.RoundTripper, error) {
+	tlsConfig, err := newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := newProxy()
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	dialContext := dialer.DialContext
+	if path := socketPath(); path != "" {
+		// Every request goes to the socket, whatever host its link names.
+		dialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		}
+	}
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   connectTimeout,
+		ResponseHeaderTimeout: readTimeout,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          10,
+	}, nil
+}
+
+// newProxy uses the --proxy URL when given, and otherwise the proxy named by
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables. Requests
+// over a Unix socket are never proxied.
+func newProxy() (func(*http.Request) (*url.URL, error), error) {
+	if socketPath() != "" {
+		return nil, nil
+	}
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxyURL)
+	}
+	return http.ProxyURL(parsed), nil
+}
+
+// newTLSConfig trusts the system roots plus any --cacert bundle, presents the
+// --cert and --key pair when given and, with --insecure-skip-verify, trusts
+// any server at all.
+func newTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if caCertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		contents, err := ioutil.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %v", err)
+		}
+		if !pool.AppendCertsFromPEM(contents) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caCertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if clientCertFile != "" || clientKeyFile != "" {
+		if clientCertFile == "" || clientKeyFile == "" {
+			return nil, fmt.Errorf("--cert and --key must be given together")
+		}
+		certificate, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	if insecureSkipVerify {
+		logger.Warn("TLS CERTIFICATE VERIFICATION IS DISABLED: the server's identity is not checked and the connection can be intercepted")
+		tlsConfig.InsecureSkipVerify = true
+	}
+	return tlsConfig, nil
+}
+
+// configFlags are persistent flags whose defaults can be set in the config
+// file under the same names.
+var configFlags = []string{
+	"connect-timeout",
+	"read-timeout",
+	"retries",
+	"retry-backoff",
+	"cacert",
+	"cert",
+	"key",
+	"insecure-skip-verify",
+	"proxy",
+}
+
+// applyConfigDefaults sets every config flag that was not given on the
+// command line to its value from the config file, if it has one.
+func applyConfigDefaults(flags *pflag.FlagSet) error {
+	for _, name := range configFlags {
+		flag := flags.Lookup(name)
+		if flag == nil || flag.Changed || !viper.IsSet(name) {
+			continue
+		}
+		err := flag.Value.Set(viper.GetString(name))
+		if err != nil {
+			return fmt.Errorf("invalid %s in config file: %v", name, err)
+		}
+	}
+	return nil
+}
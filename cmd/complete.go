@@ -0,0 +1,56 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// completeCmd represents the complete command
+var completeCmd = &cobra.Command{
+	Use:   "complete <number>",
+	Short: "Complete a todo",
+	Long: `Complete the todo with the given number in the now list, or in the later
+list with --later, and show how the list changed. Numbers are the ones shown
+by the list command. Offline, the completion is queued until sync.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		number, err := strconv.Atoi(args[0])
+		if err != nil {
+			logger.Error(fmt.Sprintf("invalid todo number %q", args[0]))
+			return
+		}
+		runOperation(ctx, func(list List) (QueuedOperation, error) {
+			todo, err := numberedTodo(list, laterTodos, number)
+			if err != nil {
+				return QueuedOperation{}, err
+			}
+			return QueuedOperation{Action: "complete", Later: laterTodos, Task: todo.Task}, nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completeCmd)
+
+	addListFlag(completeCmd)
+	addLaterFlag(completeCmd)
+	addStrategyFlag(completeCmd)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	completedSince string
+	completedUntil string
+)
+
+// completedCmd represents the completed command
+var completedCmd = &cobra.Command{
+	Use:   "completed",
+	Short: "Show the history of completed todos",
+	Long: `Show the history of completed todos, one per line, with the time each
+todo was completed. The history can be narrowed with --since and --until,
+both of which take a date in the form YYYY-MM-DD and are inclusive.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		since, until, err := completedRange(completedSince, completedUntil)
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		render(CompletedTodosResult{Todos: getCompletedTodos(ctx, completedListLink(ctx), since, until)})
+	},
+}
+
+// CompletedTodosResult is the completed history, oldest page first.
+type CompletedTodosResult struct {
+	Todos []CompletedTodo `json:"todos"`
+}
+
+func (result CompletedTodosResult) String() string {
+	return result.text(terminal{})
+}
+
+func (result CompletedTodosResult) text(t terminal) string {
+	var builder strings.Builder
+	for _, todo := range result.Todos {
+		completedAt := todo.CompletedAt.Local().Format("2006-01-02 15:04")
+		fmt.Fprintf(&builder, "%s  %s\n", t.paint(colorGray, completedAt), t.wrap(todo.Task, len(completedAt)+2))
+	}
+	return builder.String()
+}
+
+func (result CompletedTodosResult) Columns() []string {
+	return []string{"completedAt", "task"}
+}
+
+func (result CompletedTodosResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Todos))
+	for _, todo := range result.Todos {
+		rows = append(rows, []string{todo.CompletedAt.Format(time.RFC3339), todo.Task})
+	}
+	return rows
+}
+
+// completedListLink finds the completed history of the selected list, or the
+// history advertised by the root resources when no list is selected.
+func completedListLink(ctx context.Context) Link {
+	if selectedListName() != "" {
+		return getList(ctx).List.Links["completed"]
+	}
+	rootResources := getRootResources(ctx, Link{Href: viper.GetString("root-href")})
+	return rootResources.Links["completedList"]
+}
+
+// getCompletedTodos follows the next links of the completed list until the
+// last page, keeping the todos completed within [since, until).
+func getCompletedTodos(ctx context.Context, link Link, since time.Time, until time.Time) []CompletedTodo {
+	todos, err := fetchCompletedTodos(ctx, link, since, until)
+	if err != nil {
+		logger.Error("request failed", "error", err)
+	}
+	return todos
+}
+
+// fetchCompletedTodos is getCompletedTodos for callers that must not go on
+// with part of the history, stopping at the first page that fails.
+func fetchCompletedTodos(ctx context.Context, link Link, since time.Time, until time.Time) ([]CompletedTodo, error) {
+	todos := make([]CompletedTodo, 0)
+	for link.Href != "" {
+		var completedListResponse CompletedListResponse
+		if err := fetchResource(ctx, "GET", link, nil, &completedListResponse); err != nil {
+			return todos, err
+		}
+		for _, todo := range completedListResponse.List.Todos {
+			if !since.IsZero() && todo.CompletedAt.Before(since) {
+				continue
+			}
+			if !until.IsZero() && !todo.CompletedAt.Before(until) {
+				continue
+			}
+			todos = append(todos, todo)
+		}
+		link = completedListResponse.Links["next"]
+	}
+	return todos, nil
+}
+
+func completedRange(sinceValue string, untilValue string) (time.Time, time.Time, error) {
+	var since, until time.Time
+	var err error
+	if sinceValue != "" {
+		since, err = time.ParseInLocation(dateLayout, sinceValue, time.Local)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid --since date %q, expected YYYY-MM-DD", sinceValue)
+		}
+	}
+	if untilValue != "" {
+		until, err = time.ParseInLocation(dateLayout, untilValue, time.Local)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid --until date %q, expected YYYY-MM-DD", untilValue)
+		}
+		until = until.AddDate(0, 0, 1)
+	}
+	return since, until, nil
+}
+
+func init() {
+	rootCmd.AddCommand(completedCmd)
+
+	addListFlag(completedCmd)
+	completedCmd.Flags().StringVar(&completedSince, "since", "", "only show todos completed on or after this date (YYYY-MM-DD)")
+	completedCmd.Flags().StringVar(&completedUntil, "until", "", "only show todos completed on or before this date (YYYY-MM-DD)")
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// lastETag is the strong ETag of the last resource fetched. Every mutation
+// follows a link of the resource fetched just before it, so this is the
+// version of the resource the mutation was derived from.
+var lastETag string
+
+// maxMutationRetries is how many times a mutation is sent again after the
+// server answered that the list changed, so that a server that keeps
+// refusing it cannot keep the CLI busy for ever.
+const maxMutationRetries = 5
+
+// errNotRetried is returned when a mutation the server refused because the
+// list changed is not sent again.
+var errNotRetried = errors.New("the list changed on the server and the change was not retried")
+
+// rememberETag keeps the ETag of a response to a GET. Weak ETags never match
+// an If-Match, so they are forgotten instead.
+func rememberETag(etag string) {
+	if strings.HasPrefix(etag, "W/") {
+		etag = ""
+	}
+	lastETag = etag
+}
+
+func isPreconditionFailed(err error) bool {
+	var statusErr *statusError
+	return errors.As(err, &statusErr) && statusErr.code == http.StatusPreconditionFailed
+}
+
+// sendListMutation sends a request derived from a list. When the server
+// answers that the list changed since it was fetched, it shows what changed
+// and asks whether to send the request again, derived from the new version.
+// It returns the version of the list the request was last derived from, and
+// the server's refusal once the request was sent again maxMutationRetries
+// times.
+func sendListMutation(ctx context.Context, name string, before ListResponse, send func(list List) error) (ListResponse, error) {
+	err := send(before.List)
+	for retries := 0; isPreconditionFailed(err); retries++ {
+		if retries == maxMutationRetries {
+			return before, err
+		}
+		current, fetchErr := fetchList(ctx, name)
+		if fetchErr != nil {
+			return before, fetchErr
+		}
+		if !confirmRetry(before.List, current.List) {
+			return current, errNotRetried
+		}
+		before = current
+		err = send(current.List)
+	}
+	return before, err
+}
+
+// confirmRetry shows how a list changed and asks whether to try again, or
+// decides with --strategy when it is not ask.
+func confirmRetry(before List, current List) bool {
+	fmt.Fprintln(promptWriter(), "The list was changed on the server:")
+	render(listDiff("changed", before, current))
+	switch conflictStrategy {
+	case strategyMine:
+		return true
+	case strategyTheirs, strategyAbort:
+		return false
+	}
+	fmt.Fprint(promptWriter(), "Retry? [y/N] ")
+	if !conflictInput.Scan() {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(conflictInput.Text()))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	strategyAsk    = "ask"
+	strategyMine   = "mine"
+	strategyTheirs = "theirs"
+	strategyAbort  = "abort"
+)
+
+var (
+	conflictStrategy string
+	conflictInput    = bufio.NewScanner(os.Stdin)
+)
+
+func addStrategyFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&conflictStrategy, "strategy", strategyAsk, "how to resolve conflicts with changes made on the server: ask, mine, theirs or abort")
+}
+
+func checkStrategy() error {
+	switch conflictStrategy {
+	case strategyAsk, strategyMine, strategyTheirs, strategyAbort:
+		return nil
+	}
+	return fmt.Errorf("unknown --strategy %q, expected ask, mine, theirs or abort", conflictStrategy)
+}
+
+func snapshotPath() string {
+	return filepath.Join(offlineDir(), "snapshots.json")
+}
+
+// saveSnapshot records a list as it was shown, so that the todo numbers given
+// to later commands can be read the way they were seen.
+func saveSnapshot(name string, list List) {
+	saveList(snapshotPath(), name, ListResponse{List: list})
+}
+
+// viewedList is the list as it was last shown, or the given list when it has
+// not been shown yet.
+func viewedList(name string, current List) List {
+	if shown, ok := loadLists(snapshotPath()).Lists[name]; ok {
+		return shown.List.List
+	}
+	return current
+}
+
+func sectionTasks(list List, later bool) []string {
+	if later {
+		return tasks(list.DeferredTodos)
+	}
+	return tasks(list.Todos)
+}
+
+// conflict is a change made on the server to the todo an operation is about
+// since the list the operation was made against was shown.
+type conflict struct {
+	operation QueuedOperation
+	theirs    string
+}
+
+// detectConflict compares the list an operation was made against with the
+// current one. Changes to other todos do not conflict; adding a todo the
+// server has since gained, or moving one whose place among the others has
+// since changed, does.
+func detectConflict(operation QueuedOperation, current List) *conflict {
+	if operation.Base == nil {
+		return nil
+	}
+	currentTasks := sectionTasks(current, operation.Later)
+	switch operation.Action {
+	case "add":
+		if indexOf(currentTasks, operation.Task) >= 0 && indexOf(operation.Base, operation.Task) < 0 {
+			return &conflict{operation: operation, theirs: fmt.Sprintf("%q was also added on the server", operation.Task)}
+		}
+	case "move":
+		index := indexOf(currentTasks, operation.Task)
+		if index < 0 || indexOf(operation.Base, operation.Task) < 0 {
+			return nil
+		}
+		if rankAmongCommon(operation.Base, currentTasks, operation.Task) != rankAmongCommon(currentTasks, operation.Base, operation.Task) {
+			return &conflict{operation: operation, theirs: fmt.Sprintf("%q was moved to position %d on the server", operation.Task, index+1)}
+		}
+	}
+	return nil
+}
+
+// rankAmongCommon counts the tasks before task in tasks that are also in
+// others.
+func rankAmongCommon(tasks []string, others []string, task string) int {
+	rank := 0
+	for _, candidate := range tasks {
+		if candidate == task {
+			return rank
+		}
+		if indexOf(others, candidate) >= 0 {
+			rank++
+		}
+	}
+	return rank
+}
+
+// targetPosition translates the position of a move, given against the list
+// as it was shown, to the current list by keeping the todo it was to be
+// placed before.
+func targetPosition(operation QueuedOperation, current List) int {
+	if operation.Base == nil {
+		return operation.Position
+	}
+	others := without(operation.Base, operation.Task)
+	currentOthers := without(sectionTasks(current, operation.Later), operation.Task)
+	index := operation.Position - 1
+	if index < 0 {
+		return 1
+	}
+	if index >= len(others) {
+		return len(currentOthers) + 1
+	}
+	if anchor := indexOf(currentOthers, others[index]); anchor >= 0 {
+		return anchor + 1
+	}
+	return operation.Position
+}
+
+// resolveConflict decides between the operation and the server's version
+// using --strategy, asking when it is ask. Running out of input aborts.
+func resolveConflict(found *conflict) string {
+	if conflictStrategy != strategyAsk {
+		return conflictStrategy
+	}
+	fmt.Fprintf(promptWriter(), "Conflict: %s\n  yours: %s\nKeep [m]ine, keep [t]heirs or [a]bort? ", found.theirs, found.operation)
+	for conflictInput.Scan() {
+		switch strings.ToLower(strings.TrimSpace(conflictInput.Text())) {
+		case "m", "mine":
+			return strategyMine
+		case "t", "theirs":
+			return strategyTheirs
+		case "a", "abort":
+			return strategyAbort
+		}
+		fmt.Fprint(promptWriter(), "Please answer m, t or a: ")
+	}
+	return strategyAbort
+}
+
+func indexOf(tasks []string, task string) int {
+	for i, candidate := range tasks {
+		if candidate == task {
+			return i
+		}
+	}
+	return -1
+}
+
+func without(tasks []string, task string) []string {
+	result := make([]string, 0, len(tasks))
+	for _, candidate := range tasks {
+		if candidate != task {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
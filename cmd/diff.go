@@ -0,0 +1,132 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ListDiffResult shows the now and later todos of a list before and after a
+// change such as an undo or redo.
+type ListDiffResult struct {
+	Action  string       `json:"action"`
+	Before  List         `json:"before"`
+	After   List         `json:"after"`
+	Changes []TaskChange `json:"changes"`
+}
+
+// TaskChange is a single line of a list diff. Change is "-" for a removed
+// task, "+" for an added task and " " for an unchanged one.
+type TaskChange struct {
+	List   string `json:"list"`
+	Change string `json:"change"`
+	Task   string `json:"task"`
+}
+
+func listDiff(action string, before List, after List) ListDiffResult {
+	changes := diffTasks(nowName(before), tasks(before.Todos), tasks(after.Todos))
+	changes = append(changes, diffTasks(deferredName(before), tasks(before.DeferredTodos), tasks(after.DeferredTodos))...)
+	return ListDiffResult{Action: action, Before: before, After: after, Changes: changes}
+}
+
+var changeColors = map[string]string{"-": colorRed, "+": colorGreen}
+
+func (result ListDiffResult) String() string {
+	return result.text(terminal{})
+}
+
+func (result ListDiffResult) text(t terminal) string {
+	var builder strings.Builder
+	listColors := []string{colorGreen, colorBlue}
+	for i, list := range []string{nowName(result.Before), deferredName(result.Before)} {
+		fmt.Fprintf(&builder, "%s:\n", t.paint(listColors[i], list))
+		for _, change := range result.Changes {
+			if change.List != list {
+				continue
+			}
+			line := change.Change + " " + t.wrap(change.Task, 2)
+			if color, ok := changeColors[change.Change]; ok {
+				line = t.paint(color, line)
+			}
+			fmt.Fprintln(&builder, line)
+		}
+	}
+	return builder.String()
+}
+
+func (result ListDiffResult) Columns() []string {
+	return []string{"list", "change", "task"}
+}
+
+func (result ListDiffResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		rows = append(rows, []string{change.List, change.Change, change.Task})
+	}
+	return rows
+}
+
+func tasks(todos []Todo) []string {
+	result := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		result = append(result, todo.Task)
+	}
+	return result
+}
+
+// diffTasks returns a line based diff of two task lists using their longest
+// common subsequence.
+func diffTasks(list string, before []string, after []string) []TaskChange {
+	lengths := make([][]int, len(before)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	changes := make([]TaskChange, 0, len(before)+len(after))
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			changes = append(changes, TaskChange{List: list, Change: " ", Task: before[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			changes = append(changes, TaskChange{List: list, Change: "-", Task: before[i]})
+			i++
+		default:
+			changes = append(changes, TaskChange{List: list, Change: "+", Task: after[j]})
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		changes = append(changes, TaskChange{List: list, Change: "-", Task: before[i]})
+	}
+	for ; j < len(after); j++ {
+		changes = append(changes, TaskChange{List: list, Change: "+", Task: after[j]})
+	}
+	return changes
+}
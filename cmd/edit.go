@@ -0,0 +1,408 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var editYes bool
+
+const editInstructions = `# Edit the list, one todo per line, then save and quit to apply the changes.
+# Change the text after an [id] to change a todo, reorder the lines to move
+# todos, delete a line to delete its todo and add a line without an id to
+# add a todo. Lines starting with # are ignored, except the section headers.
+`
+
+// editCmd represents the edit command
+var editCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the list in $EDITOR",
+	Long: `Open the now and later todos of the list in $EDITOR, one per line with an id.
+When the editor is closed, the fewest adds, updates, deletes and moves that
+turn the list into the edited one are shown, and sent to the server once
+confirmed, or right away with --yes. Moving a todo between the now and later
+sections deletes it from one and adds it to the other.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		name := selectedListName()
+		before, err := fetchList(ctx, name)
+		if err != nil {
+			logger.Error("request failed", "error", err)
+			return
+		}
+		file, err := ioutil.TempFile("", "doer-edit-*.txt")
+		if err != nil {
+			logger.Error("creating file to edit", "error", err)
+			return
+		}
+		path := file.Name()
+		defer os.Remove(path)
+		_, err = file.WriteString(editText(before.List))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			logger.Error("writing file to edit", "path", path, "error", err)
+			return
+		}
+		if err := runEditor(path); err != nil {
+			logger.Error("running editor", "error", err)
+			return
+		}
+		edited, err := ioutil.ReadFile(path)
+		if err != nil {
+			logger.Error("reading edited file", "path", path, "error", err)
+			return
+		}
+		lines, err := parseEditText(string(edited))
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		steps, err := planEdit(before.List, lines)
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		if len(steps) == 0 {
+			render(MessageResult{Message: "Nothing to change"})
+			return
+		}
+		if !confirmEdit(steps) {
+			render(MessageResult{Message: "Nothing was changed"})
+			return
+		}
+		// The edit was made against the list as it was before the editor
+		// opened, so it no longer applies if the list changed since.
+		current, err := fetchList(ctx, name)
+		if err != nil {
+			logger.Error("request failed", "error", err)
+			return
+		}
+		if !reflect.DeepEqual(editSections(current.List), editSections(before.List)) {
+			render(listDiff("changed", before.List, current.List))
+			logger.Error("the list was changed on the server while it was being edited, nothing was changed")
+			return
+		}
+		for i, step := range steps {
+			list, err := fetchList(ctx, name)
+			if err == nil {
+				err = sendEditStep(ctx, list.List, step)
+			}
+			if err != nil {
+				logger.Error("edit stopped", "operation", step.String(), "remaining", len(steps)-i, "error", err)
+				break
+			}
+		}
+		after, err := fetchList(ctx, name)
+		if err != nil {
+			logger.Error("fetching list", "error", err)
+			return
+		}
+		saveSnapshot(name, after.List)
+		render(listDiff("edit", before.List, after.List))
+	},
+}
+
+// editLine is a line of the edited file. ID is 0 for a line added in the
+// editor.
+type editLine struct {
+	ID    int
+	Later bool
+	Task  string
+}
+
+// editStep is an operation of an edit with the position, within its section,
+// that the todo it changes will have when it is sent. Todos can share their
+// text, so the position rather than the text tells which todo is meant.
+// Index is -1 for an add.
+type editStep struct {
+	QueuedOperation
+	Index int
+}
+
+var editIDLine = regexp.MustCompile(`^\[(\d+)\] ?(.*)$`)
+
+// editText writes the list for editing, numbering the todos of both
+// sections from 1 so that each keeps its id wherever it is moved.
+func editText(list List) string {
+	var builder strings.Builder
+	builder.WriteString(editInstructions)
+	id := 1
+	for _, later := range []bool{false, true} {
+		fmt.Fprintf(&builder, "\n## %s\n", sectionName(list, later))
+		for _, task := range sectionTasks(list, later) {
+			fmt.Fprintf(&builder, "[%d] %s\n", id, task)
+			id++
+		}
+	}
+	return builder.String()
+}
+
+// editSections lists the todos in the order editText numbers them.
+func editSections(list List) []string {
+	return append(sectionTasks(list, false), sectionTasks(list, true)...)
+}
+
+// parseEditText reads the edited file. The first section header starts the
+// now todos and the second the later ones.
+func parseEditText(text string) ([]editLine, error) {
+	lines := make([]editLine, 0)
+	section := 0
+	seen := make(map[int]bool)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "## "):
+			section++
+			if section > 2 {
+				return nil, fmt.Errorf("the edited list has more than two sections")
+			}
+			continue
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		}
+		edited := editLine{Later: section == 2, Task: line}
+		if match := editIDLine.FindStringSubmatch(line); match != nil {
+			edited.ID, _ = strconv.Atoi(match[1])
+			edited.Task = strings.TrimSpace(match[2])
+			if seen[edited.ID] {
+				return nil, fmt.Errorf("todo [%d] appears more than once in the edited list", edited.ID)
+			}
+			seen[edited.ID] = true
+		}
+		if edited.Task != "" {
+			lines = append(lines, edited)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// planEdit works out the operations that turn a list into the edited one:
+// deletes, then updates, then adds to the end of each section, and last the
+// fewest moves, which leave the longest run of todos already in order in
+// place.
+func planEdit(list List, lines []editLine) ([]editStep, error) {
+	original := editSections(list)
+	nowCount := len(sectionTasks(list, false))
+	for _, line := range lines {
+		if line.ID > len(original) {
+			return nil, fmt.Errorf("there is no todo [%d] in the list", line.ID)
+		}
+	}
+	later := func(id int) bool { return id > nowCount }
+	kept := make(map[int]editLine)
+	for _, line := range lines {
+		if line.ID > 0 && later(line.ID) == line.Later {
+			kept[line.ID] = line
+		}
+	}
+	// The ids in each section, in order, as the steps before the one being
+	// planned leave them.
+	order := map[bool][]int{false: {}, true: {}}
+	for id := 1; id <= len(original); id++ {
+		order[later(id)] = append(order[later(id)], id)
+	}
+	deletes := make([]editStep, 0)
+	updates := make([]editStep, 0)
+	adds := make([]editStep, 0)
+	moves := make([]editStep, 0)
+	for id := 1; id <= len(original); id++ {
+		if _, ok := kept[id]; !ok {
+			section := later(id)
+			index := indexOfKey(order[section], id)
+			deletes = append(deletes, editStep{QueuedOperation{Action: "delete", Later: section, Task: original[id-1]}, index})
+			order[section] = withoutKey(order[section], id)
+		}
+	}
+	for id := 1; id <= len(original); id++ {
+		if line, ok := kept[id]; ok && line.Task != original[id-1] {
+			section := later(id)
+			updates = append(updates, editStep{QueuedOperation{Action: "update", Later: section, Task: original[id-1], NewTask: line.Task}, indexOfKey(order[section], id)})
+		}
+	}
+	for _, section := range []bool{false, true} {
+		// Todos are keyed by id, and added ones by their line, counted
+		// past the ids.
+		current := make([]int, 0)
+		target := make([]int, 0)
+		text := make(map[int]string)
+		for id := 1; id <= len(original); id++ {
+			if line, ok := kept[id]; ok && later(id) == section {
+				current = append(current, id)
+				text[id] = line.Task
+			}
+		}
+		for i, line := range lines {
+			if line.Later != section {
+				continue
+			}
+			key := line.ID
+			if _, ok := kept[key]; !ok {
+				key = len(original) + 1 + i
+				current = append(current, key)
+				text[key] = line.Task
+				adds = append(adds, editStep{QueuedOperation{Action: "add", Later: section, Task: line.Task}, -1})
+			}
+			target = append(target, key)
+		}
+		moves = append(moves, planMoves(current, target, text, section)...)
+	}
+	operations := append(deletes, updates...)
+	operations = append(operations, adds...)
+	return append(operations, moves...), nil
+}
+
+// planMoves moves every todo that is not part of the longest run already in
+// the target order, each right after the todo that precedes it in the
+// target.
+func planMoves(current []int, target []int, text map[int]string, later bool) []editStep {
+	rank := make(map[int]int)
+	for i, key := range target {
+		rank[key] = i
+	}
+	inPlace := make(map[int]bool)
+	for _, key := range longestIncreasing(current, rank) {
+		inPlace[key] = true
+	}
+	moves := make([]editStep, 0)
+	order := append([]int{}, current...)
+	for i, key := range target {
+		if inPlace[key] {
+			continue
+		}
+		index := indexOfKey(order, key)
+		order = withoutKey(order, key)
+		position := 1
+		if i > 0 {
+			position = indexOfKey(order, target[i-1]) + 2
+		}
+		order = append(order[:position-1], append([]int{key}, order[position-1:]...)...)
+		moves = append(moves, editStep{QueuedOperation{Action: "move", Later: later, Task: text[key], Position: position}, index})
+	}
+	return moves
+}
+
+// longestIncreasing finds a longest subsequence of keys whose ranks
+// increase.
+func longestIncreasing(keys []int, rank map[int]int) []int {
+	lengths := make([]int, len(keys))
+	previous := make([]int, len(keys))
+	best := -1
+	for i := range keys {
+		lengths[i], previous[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if rank[keys[j]] < rank[keys[i]] && lengths[j]+1 > lengths[i] {
+				lengths[i], previous[i] = lengths[j]+1, j
+			}
+		}
+		if best < 0 || lengths[i] > lengths[best] {
+			best = i
+		}
+	}
+	result := make([]int, 0)
+	for i := best; i >= 0; i = previous[i] {
+		result = append([]int{keys[i]}, result...)
+	}
+	return result
+}
+
+func indexOfKey(keys []int, key int) int {
+	for i, candidate := range keys {
+		if candidate == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func withoutKey(keys []int, key int) []int {
+	result := make([]int, 0, len(keys))
+	for _, candidate := range keys {
+		if candidate != key {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// runEditor opens a file in $EDITOR, or vi when it is not set, and waits for
+// it to close.
+func runEditor(path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	command := exec.Command(editor[0], append(editor[1:], path)...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+// sendEditStep sends an operation of an edit to the todo at the position it
+// was planned for, rather than to the first todo with the same text.
+func sendEditStep(ctx context.Context, list List, step editStep) error {
+	if step.Index < 0 {
+		return sendOperation(ctx, list, step.QueuedOperation)
+	}
+	todos := list.Todos
+	if step.Later {
+		todos = list.DeferredTodos
+	}
+	if step.Index >= len(todos) || todos[step.Index].Task != step.Task {
+		return notApplicable("todo %q is no longer at %d in the %s list", step.Task, step.Index+1, sectionName(list, step.Later))
+	}
+	return sendTodoOperation(ctx, list, todos[step.Index], step.QueuedOperation)
+}
+
+// confirmEdit shows the operations an edit takes and asks whether to send
+// them, unless --yes was given.
+func confirmEdit(steps []editStep) bool {
+	for _, step := range steps {
+		fmt.Fprintf(promptWriter(), "  %s\n", step)
+	}
+	if editYes {
+		return true
+	}
+	fmt.Fprint(promptWriter(), "Apply these changes? [y/N] ")
+	if !conflictInput.Scan() {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(conflictInput.Text()))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+
+	addListFlag(editCmd)
+	editCmd.Flags().BoolVarP(&editYes, "yes", "y", false, "apply the changes without asking")
+}
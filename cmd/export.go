@@ -0,0 +1,148 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	exportFormat   string
+	exportFile     string
+	exportSections []string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a list to JSON, CSV, Markdown, todo.txt or iCalendar",
+	Long: `Write the now, later and completed todos of a list to stdout, or to a file
+with --file, in the format given with --format:
+
+  json      everything in the list, versioned, for backups and scripts
+  csv       task, later, completed and completedAt columns
+  markdown  a checklist with a section for each part of the list
+  todotxt   todo.txt lines, with later todos in a project
+  ical      an iCalendar file with a VTODO for each todo
+
+Parts of the list can be left out with --include.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		writer, ok := todoWriters[exportFormat]
+		if !ok {
+			logger.Error("unknown export format", "format", exportFormat, "expected", strings.Join(exportFormats(), ", "))
+			return
+		}
+		include := make(map[string]bool)
+		for _, section := range exportSections {
+			if section != "now" && section != "later" && section != "completed" {
+				logger.Error("unknown --include section, expected now, later or completed", "section", section)
+				return
+			}
+			include[section] = true
+		}
+		export, err := exportList(ctx, include)
+		var notApplicableErr *notApplicableError
+		if errors.As(err, &notApplicableErr) {
+			logger.Error(err.Error())
+			return
+		} else if err != nil {
+			logger.Error("request failed", "error", err)
+			return
+		}
+		var contents bytes.Buffer
+		if err := writer.Write(&contents, export); err != nil {
+			logger.Error("writing export", "format", exportFormat, "error", err)
+			return
+		}
+		if exportFile == "" {
+			os.Stdout.Write(contents.Bytes())
+			return
+		}
+		if err := writeFileAtomically(exportFile, contents.Bytes(), 0644); err != nil {
+			logger.Error("writing export", "path", exportFile, "error", err)
+			return
+		}
+		render(MessageResult{Message: fmt.Sprintf("Exported %d todos to %s", len(export.Todos)+len(export.DeferredTodos)+len(export.Completed), exportFile)})
+	},
+}
+
+// exportList gathers the included parts of the selected list. Only the
+// default list falls back to the completed list of the root resources; that
+// history belongs to no other list.
+func exportList(ctx context.Context, include map[string]bool) (Export, error) {
+	name := selectedListName()
+	listResponse, err := fetchList(ctx, name)
+	if err != nil {
+		return Export{}, err
+	}
+	var completed Link
+	if name == "" {
+		var rootResources ResourcesResponse
+		if err := fetchResource(ctx, "GET", Link{Href: viper.GetString("root-href")}, nil, &rootResources); err != nil {
+			return Export{}, err
+		}
+		completed = rootResources.Links["completedList"]
+	}
+	return exportOf(ctx, listResponse.List, completed, include)
+}
+
+// exportOf makes an export of the included parts of a list, following the
+// list's completed link, or the given one when the list has none.
+func exportOf(ctx context.Context, list List, completed Link, include map[string]bool) (Export, error) {
+	export := Export{
+		Version:      exportVersion,
+		ExportedAt:   time.Now().UTC(),
+		Name:         nowName(list),
+		DeferredName: deferredName(list),
+	}
+	if include["now"] {
+		export.Todos = tasks(list.Todos)
+	}
+	if include["later"] {
+		export.DeferredTodos = tasks(list.DeferredTodos)
+	}
+	if include["completed"] {
+		if link, ok := list.Links["completed"]; ok {
+			completed = link
+		}
+		todos, err := fetchCompletedTodos(ctx, completed, time.Time{}, time.Time{})
+		if err != nil {
+			return export, err
+		}
+		export.Completed = todos
+	}
+	return export, nil
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	addListFlag(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", "json", "format to export in: "+strings.Join(exportFormats(), ", "))
+	exportCmd.Flags().StringVar(&exportFile, "file", "", "file to write the export to instead of stdout")
+	exportCmd.Flags().StringSliceVar(&exportSections, "include", []string{"now", "later", "completed"}, "parts of the list to export: now, later and completed")
+}
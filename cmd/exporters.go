@@ -0,0 +1,237 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// exportVersion is bumped whenever the JSON export changes in a way older
+// readers would misread.
+const exportVersion = 1
+
+// Export is a list as exported, holding everything needed to recreate it.
+// Sections left out with --include are nil.
+type Export struct {
+	Version       int             `json:"version"`
+	ExportedAt    time.Time       `json:"exportedAt"`
+	Name          string          `json:"name"`
+	DeferredName  string          `json:"deferredName"`
+	Todos         []string        `json:"todos"`
+	DeferredTodos []string        `json:"deferredTodos"`
+	Completed     []CompletedTodo `json:"completed"`
+}
+
+// TodoWriter writes an export in one format.
+type TodoWriter interface {
+	Write(w io.Writer, export Export) error
+}
+
+var todoWriters = map[string]TodoWriter{
+	"json":     jsonWriter{},
+	"csv":      csvWriter{},
+	"markdown": markdownWriter{},
+	"todotxt":  todoTxtWriter{},
+	"ical":     icalWriter{},
+}
+
+func exportFormats() []string {
+	formats := make([]string, 0, len(todoWriters))
+	for format := range todoWriters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+type jsonWriter struct{}
+
+func (jsonWriter) Write(w io.Writer, export Export) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+// csvWriter writes the columns import reads by default, so that an export
+// can be imported again with --later-column later --completed-column
+// completed.
+type csvWriter struct{}
+
+func (csvWriter) Write(w io.Writer, export Export) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"task", "later", "completed", "completedAt"})
+	for _, task := range export.Todos {
+		writer.Write([]string{task, "", "", ""})
+	}
+	for _, task := range export.DeferredTodos {
+		writer.Write([]string{task, "x", "", ""})
+	}
+	for _, todo := range export.Completed {
+		writer.Write([]string{todo.Task, "", "x", todo.CompletedAt.Format(time.RFC3339)})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// markdownWriter writes a checklist with a heading for each section.
+type markdownWriter struct{}
+
+func (markdownWriter) Write(w io.Writer, export Export) error {
+	var builder strings.Builder
+	section := func(heading string, tasks []string, mark string) {
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		fmt.Fprintf(&builder, "## %s\n\n", heading)
+		for _, task := range tasks {
+			fmt.Fprintf(&builder, "- [%s] %s\n", mark, task)
+		}
+	}
+	if export.Todos != nil {
+		section(export.Name, export.Todos, " ")
+	}
+	if export.DeferredTodos != nil {
+		section(export.DeferredName, export.DeferredTodos, " ")
+	}
+	if export.Completed != nil {
+		section("completed", completedTasks(export.Completed), "x")
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// todoTxtWriter writes todo.txt lines. Later todos are tagged with the
+// later list's name as a project, and completed ones carry their date.
+type todoTxtWriter struct{}
+
+func (todoTxtWriter) Write(w io.Writer, export Export) error {
+	var builder strings.Builder
+	for _, task := range export.Todos {
+		fmt.Fprintf(&builder, "%s\n", task)
+	}
+	for _, task := range export.DeferredTodos {
+		fmt.Fprintf(&builder, "%s +%s\n", task, strings.ReplaceAll(export.DeferredName, " ", "-"))
+	}
+	for _, todo := range export.Completed {
+		fmt.Fprintf(&builder, "x %s %s\n", todo.CompletedAt.Local().Format(dateLayout), todo.Task)
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// icalWriter writes an RFC 5545 calendar with a VTODO for each todo. Later
+// todos are put in a category named after the later list.
+type icalWriter struct{}
+
+func (icalWriter) Write(w io.Writer, export Export) error {
+	var builder strings.Builder
+	stamp := export.ExportedAt.UTC().Format(icalTimeLayout)
+	line := func(content string) {
+		builder.WriteString(foldICalLine(content))
+	}
+	// The same task can be in a list more than once, so each occurrence
+	// is named with how many came before it.
+	occurrences := make(map[string]int)
+	vtodo := func(task string, category string, completedAt time.Time) {
+		occurrences[task]++
+		line("BEGIN:VTODO")
+		line("UID:" + icalUID(export.Name, task, occurrences[task]))
+		line("DTSTAMP:" + stamp)
+		line("SUMMARY:" + escapeICalText(task))
+		if category != "" {
+			line("CATEGORIES:" + escapeICalText(category))
+		}
+		if completedAt.IsZero() {
+			line("STATUS:NEEDS-ACTION")
+		} else {
+			line("STATUS:COMPLETED")
+			line("COMPLETED:" + completedAt.UTC().Format(icalTimeLayout))
+		}
+		line("END:VTODO")
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//doer-cli//export//EN")
+	line("X-WR-CALNAME:" + escapeICalText(export.Name))
+	for _, task := range export.Todos {
+		vtodo(task, "", time.Time{})
+	}
+	for _, task := range export.DeferredTodos {
+		vtodo(task, export.DeferredName, time.Time{})
+	}
+	for _, todo := range export.Completed {
+		vtodo(todo.Task, "", todo.CompletedAt)
+	}
+	line("END:VCALENDAR")
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+const icalTimeLayout = "20060102T150405Z"
+
+// icalUID names a todo the same way in every export of a list, so calendar
+// apps update todos they already have instead of adding them again. It
+// leaves out where the todo is in the list, which changes as todos are
+// moved, added or completed.
+func icalUID(list string, task string, occurrence int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%d", list, task, occurrence)))
+	return hex.EncodeToString(sum[:16]) + "@doer-cli"
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "")
+
+func escapeICalText(text string) string {
+	return icalEscaper.Replace(text)
+}
+
+// foldICalLine ends a content line with CRLF, folding it so no line is longer
+// than 75 octets without splitting a UTF-8 sequence.
+func foldICalLine(content string) string {
+	var builder strings.Builder
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(content[cut]) {
+			cut--
+		}
+		builder.WriteString(content[:cut] + "\r\n ")
+		content = content[cut:]
+		// Continuation lines start with a space, which counts.
+		limit = 74
+	}
+	builder.WriteString(content + "\r\n")
+	return builder.String()
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func completedTasks(todos []CompletedTodo) []string {
+	tasks := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		tasks = append(tasks, todo.Task)
+	}
+	return tasks
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a pattern of a .gitignore file, matched against paths
+// relative to the directory of that file.
+type ignoreRule struct {
+	base    string
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules decide, the way git does, which paths a .gitignore file
+// excludes. The last rule that matches a path wins.
+type ignoreRules []ignoreRule
+
+// loadIgnoreFile reads the .gitignore file of a directory, if it has one.
+func loadIgnoreFile(dir string) ignoreRules {
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	rules := make(ignoreRules, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: dir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		// A pattern with a slash anywhere but at its end is relative to the
+		// directory of the .gitignore file; any other matches at any depth.
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		expression := globExpression(line)
+		if anchored {
+			expression = "^" + expression + "$"
+		} else {
+			expression = "(^|/)" + expression + "$"
+		}
+		pattern, err := regexp.Compile(expression)
+		if err != nil {
+			logger.Debug("ignoring unreadable .gitignore pattern", "dir", dir, "pattern", line, "error", err)
+			continue
+		}
+		rule.pattern = pattern
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// loadParentIgnoreFiles reads the .gitignore files of the directories above
+// dir up to the root of its git repository, outermost first, so that
+// scanning part of a repository ignores what the repository ignores.
+func loadParentIgnoreFiles(dir string) ignoreRules {
+	if isRepositoryRoot(dir) {
+		return nil
+	}
+	parents := make([]string, 0)
+	for current := filepath.Dir(dir); ; current = filepath.Dir(current) {
+		parents = append([]string{current}, parents...)
+		if isRepositoryRoot(current) {
+			break
+		}
+		if filepath.Dir(current) == current {
+			// Not in a repository, so no parent .gitignore applies.
+			return nil
+		}
+	}
+	rules := make(ignoreRules, 0)
+	for _, parent := range parents {
+		rules = append(rules, loadIgnoreFile(parent)...)
+	}
+	return rules
+}
+
+// repositoryRoot finds the root of the repository dir is in, if it is in
+// one.
+func repositoryRoot(dir string) (string, bool) {
+	for current := dir; ; current = filepath.Dir(current) {
+		if isRepositoryRoot(current) {
+			return current, true
+		}
+		if filepath.Dir(current) == current {
+			return "", false
+		}
+	}
+}
+
+func isRepositoryRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// globExpression translates a .gitignore glob to a regular expression.
+func globExpression(glob string) string {
+	var builder strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			builder.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**"):
+			builder.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			builder.WriteString(".*")
+			i++
+		case c == '*':
+			builder.WriteString("[^/]*")
+		case c == '?':
+			builder.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				builder.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			builder.WriteString("[" + class + "]")
+			i += end
+		case c == '\\' && i+1 < len(glob):
+			i++
+			builder.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return builder.String()
+}
+
+func (rules ignoreRules) ignored(path string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		relative, err := filepath.Rel(rule.base, path)
+		if err != nil || relative == "." || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if rule.pattern.MatchString(filepath.ToSlash(relative)) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,211 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var noCache bool
+
+// cachedResponse is a response stored on disk along with what is needed to
+// decide whether it is still fresh and to revalidate it when it is not.
+type cachedResponse struct {
+	URL        string        `json:"url"`
+	StatusCode int           `json:"statusCode"`
+	Header     http.Header   `json:"header"`
+	Body       []byte        `json:"body"`
+	StoredAt   time.Time     `json:"storedAt"`
+	MaxAge     time.Duration `json:"maxAge"`
+}
+
+func (entry cachedResponse) fresh(now time.Time) bool {
+	return entry.MaxAge > 0 && now.Before(entry.StoredAt.Add(entry.MaxAge))
+}
+
+func (entry cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+// cacheControl is the subset of a Cache-Control header the cache acts on.
+type cacheControl struct {
+	noStore bool
+	noCache bool
+	maxAge  time.Duration
+}
+
+func parseCacheControl(header http.Header) cacheControl {
+	var control cacheControl
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument := directive, ""
+			if i := strings.Index(directive, "="); i >= 0 {
+				name, argument = directive[:i], strings.Trim(directive[i+1:], `" `)
+			}
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "no-store":
+				control.noStore = true
+			case "no-cache":
+				control.noCache = true
+			case "max-age":
+				if seconds, err := strconv.Atoi(argument); err == nil && seconds > 0 {
+					control.maxAge = time.Duration(seconds) * time.Second
+				}
+			}
+		}
+	}
+	return control
+}
+
+// cacheTransport keeps GET responses on disk, keyed by URL and session,
+// serving them without a request while Cache-Control says they are fresh
+// and revalidating them with If-None-Match and If-Modified-Since once they
+// are not. Any other request may change what the server would return, so it
+// drops everything cached.
+type cacheTransport struct {
+	next  http.RoundTripper
+	dir   string
+	token func() string
+	now   func() time.Time
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "GET" {
+		if err := os.RemoveAll(t.dir); err != nil {
+			logger.Warn("clearing cache", "path", t.dir, "error", err)
+		}
+		return t.next.RoundTrip(req)
+	}
+	path := t.path(req)
+	entry, cached := t.load(path)
+	if cached && entry.fresh(t.now()) {
+		logger.Debug("cache hit", "url", req.URL.String())
+		return entry.response(req), nil
+	}
+	if cached {
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	response, err := t.next.RoundTrip(req)
+	if err != nil {
+		return response, err
+	}
+	if cached && response.StatusCode == http.StatusNotModified {
+		logger.Debug("cache revalidated", "url", req.URL.String())
+		response.Body.Close()
+		for name, values := range response.Header {
+			entry.Header[name] = values
+		}
+		entry.StoredAt = t.now()
+		entry.MaxAge = freshness(parseCacheControl(response.Header))
+		t.store(path, entry)
+		return entry.response(req), nil
+	}
+	control := parseCacheControl(response.Header)
+	if response.StatusCode != http.StatusOK || control.noStore || !cacheable(response.Header, control) {
+		os.Remove(path)
+		return response, nil
+	}
+	body, err := readBody(&response.Body)
+	if err != nil {
+		return response, err
+	}
+	t.store(path, cachedResponse{
+		URL:        req.URL.String(),
+		StatusCode: response.StatusCode,
+		Header:     response.Header.Clone(),
+		Body:       body,
+		StoredAt:   t.now(),
+		MaxAge:     freshness(control),
+	})
+	return response, nil
+}
+
+// cacheable responses can either be served fresh or revalidated later.
+func cacheable(header http.Header, control cacheControl) bool {
+	return freshness(control) > 0 || header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+}
+
+func freshness(control cacheControl) time.Duration {
+	if control.noCache {
+		return 0
+	}
+	return control.maxAge
+}
+
+// path names the cache file of a request. The session token is part of the
+// key so that sessions never see each other's responses.
+func (t *cacheTransport) path(req *http.Request) string {
+	sum := sha256.Sum256([]byte(t.token() + "\n" + req.URL.String()))
+	return filepath.Join(t.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (t *cacheTransport) load(path string) (cachedResponse, bool) {
+	var entry cachedResponse
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(contents, &entry); err != nil || entry.Header == nil {
+		logger.Debug("ignoring unreadable cache entry", "path", path, "error", err)
+		return entry, false
+	}
+	return entry, true
+}
+
+// store writes an entry through a temporary file so that a reader never sees
+// half of one.
+func (t *cacheTransport) store(path string, entry cachedResponse) {
+	contents, err := json.Marshal(entry)
+	if err == nil {
+		err = writeFileAtomically(path, contents, 0600)
+	}
+	if err != nil {
+		logger.Warn("writing cache", "path", path, "error", err)
+	}
+}
+
+// cacheDir holds cached responses, in a .doer-cli/cache directory next to
+// the config file.
+func cacheDir() string {
+	return filepath.Join(filepath.Dir(cfgFile), ".doer-cli", "cache")
+}
@@ -0,0 +1,260 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat     string
+	importDryRun     bool
+	importResumeFile string
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import todos from a CSV, todo.txt or Markdown file",
+	Long: `Add the todos in a file to the list, in order. The format is taken from the
+file's extension unless --format is given:
+
+  csv       one todo per row, with columns chosen by --task-column,
+            --later-column and --completed-column
+  todotxt   todo.txt lines, ordered by priority, with "x" lines completed
+  markdown  "- [ ]" and "- [x]" checklist items
+
+With --dry-run the todos are only shown. Progress is kept in a resume file
+while importing, so running the same import again after a failure continues
+where it stopped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		path := args[0]
+		format := importFormat
+		if format == "" {
+			format = importExtensions[strings.ToLower(filepath.Ext(path))]
+		}
+		reader, ok := todoReaders[format]
+		if !ok {
+			return fmt.Errorf("unknown import format %q, use --format with one of %s", format, strings.Join(importFormats(), ", "))
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading import file: %v", err)
+		}
+		todos, err := reader.Read(bytes.NewReader(contents))
+		if err != nil {
+			return fmt.Errorf("parsing import file as %s: %v", format, err)
+		}
+		if laterTodos {
+			for i := range todos {
+				todos[i].Later = true
+			}
+		}
+		if importDryRun {
+			render(ImportResult{Todos: todos, DryRun: true})
+			return nil
+		}
+		resumePath := importResumeFile
+		if resumePath == "" {
+			resumePath = path + ".resume"
+		}
+		sum := sha256.Sum256(contents)
+		state := loadImportState(resumePath, hex.EncodeToString(sum[:]))
+		if state.Done > 0 {
+			logger.Info("resuming import", "imported", state.Done, "remaining", len(todos)-state.Done)
+		}
+		resumed := state.Done
+		progress := newProgressBar(len(todos))
+		progress.set(state.Done)
+		for state.Done < len(todos) {
+			if err := importTodo(ctx, todos[state.Done], &state, resumePath); err != nil {
+				progress.finish()
+				return fmt.Errorf("import stopped after %d todos with %d remaining, run it again to continue from %s: %v", state.Done, len(todos)-state.Done, resumePath, err)
+			}
+			progress.set(state.Done)
+		}
+		progress.finish()
+		if err := os.Remove(resumePath); err != nil && !os.IsNotExist(err) {
+			logger.Warn("removing resume file", "path", resumePath, "error", err)
+		}
+		render(ImportResult{Todos: todos[resumed:], Resumed: resumed})
+		return nil
+	},
+}
+
+// importState is what a resume file records: which file was being imported,
+// how many of its todos are done, and whether the next one was already added
+// but not yet completed.
+type importState struct {
+	Checksum string `json:"checksum"`
+	Done     int    `json:"done"`
+	Added    bool   `json:"added"`
+}
+
+// loadImportState reads a resume file, starting over when there is none or
+// it was written for a different file.
+func loadImportState(path string, checksum string) importState {
+	fresh := importState{Checksum: checksum}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fresh
+	}
+	var state importState
+	if err := json.Unmarshal(contents, &state); err != nil {
+		logger.Warn("ignoring unreadable resume file", "path", path, "error", err)
+		return fresh
+	}
+	if state.Checksum != checksum {
+		logger.Warn("ignoring resume file for a different import", "path", path)
+		return fresh
+	}
+	return state
+}
+
+func saveImportState(path string, state importState) error {
+	contents, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, append(contents, '\n'), 0600)
+}
+
+// importTodo adds a todo and then completes it if it was completed in the
+// file, recording each step in the resume file as soon as it is made.
+func importTodo(ctx context.Context, todo ImportedTodo, state *importState, resumePath string) error {
+	name := selectedListName()
+	if !state.Added {
+		list, err := fetchList(ctx, name)
+		if err != nil {
+			return err
+		}
+		if err := sendOperation(ctx, list.List, QueuedOperation{Action: "add", Later: todo.Later, Task: todo.Task}); err != nil {
+			return err
+		}
+		state.Added = true
+		if err := saveImportState(resumePath, *state); err != nil {
+			return err
+		}
+	}
+	if todo.Completed {
+		list, err := fetchList(ctx, name)
+		if err != nil {
+			return err
+		}
+		if err := completeLastTodo(ctx, list.List, todo); err != nil {
+			return err
+		}
+	}
+	state.Done++
+	state.Added = false
+	return saveImportState(resumePath, *state)
+}
+
+// completeLastTodo completes the todo that was just added, which is the last
+// one with its task should the list already have one like it.
+func completeLastTodo(ctx context.Context, list List, todo ImportedTodo) error {
+	todos := list.Todos
+	if todo.Later {
+		todos = list.DeferredTodos
+	}
+	for i := len(todos) - 1; i >= 0; i-- {
+		if todos[i].Task != todo.Task {
+			continue
+		}
+		link, ok := todos[i].Links["complete"]
+		if !ok {
+			return notApplicable("todo %q cannot be completed", todo.Task)
+		}
+		return fetchResource(ctx, "POST", link, nil, nil)
+	}
+	return notApplicable("no todo %q in the %s list", todo.Task, sectionName(list, todo.Later))
+}
+
+// ImportResult lists the todos that were imported, or would be with
+// --dry-run. Resumed counts the todos imported by an earlier run.
+type ImportResult struct {
+	Todos   []ImportedTodo `json:"todos"`
+	DryRun  bool           `json:"dryRun"`
+	Resumed int            `json:"resumed"`
+}
+
+func (result ImportResult) String() string {
+	return result.text(terminal{})
+}
+
+func (result ImportResult) text(t terminal) string {
+	var builder strings.Builder
+	for _, todo := range result.Todos {
+		marker := t.paint(colorGreen, "+")
+		if todo.Completed {
+			marker = t.paint(colorGray, "x")
+		}
+		fmt.Fprintf(&builder, "%s %s", marker, todo.Task)
+		if todo.Later {
+			builder.WriteString(t.paint(colorGray, " (later)"))
+		}
+		builder.WriteString("\n")
+	}
+	verb := "Imported"
+	if result.DryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(&builder, "%s %d todos", verb, len(result.Todos))
+	if result.Resumed > 0 {
+		fmt.Fprintf(&builder, " (%d imported before)", result.Resumed)
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+func (result ImportResult) Columns() []string {
+	return []string{"task", "later", "completed"}
+}
+
+func (result ImportResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Todos))
+	for _, todo := range result.Todos {
+		rows = append(rows, []string{todo.Task, fmt.Sprint(todo.Later), fmt.Sprint(todo.Completed)})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	addListFlag(importCmd)
+	addLaterFlag(importCmd)
+	importCmd.Flags().StringVar(&importFormat, "format", "", "format of the file: "+strings.Join(importFormats(), ", "))
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "show the todos that would be imported without importing them")
+	importCmd.Flags().StringVar(&importResumeFile, "resume-file", "", "where to keep import progress (default <file>.resume)")
+	importCmd.Flags().StringVar(&csvTaskColumn, "task-column", "task", "CSV column holding the task, by header or 1-based number")
+	importCmd.Flags().StringVar(&csvLaterColumn, "later-column", "", "CSV column marking todos for the later list")
+	importCmd.Flags().StringVar(&csvCompletedColumn, "completed-column", "", "CSV column marking completed todos")
+	importCmd.Flags().BoolVar(&csvHeader, "header", true, "whether the first CSV row is a header")
+}
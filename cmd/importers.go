@@ -0,0 +1,213 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ImportedTodo is a todo read from a file to import.
+type ImportedTodo struct {
+	Task      string `json:"task"`
+	Later     bool   `json:"later,omitempty"`
+	Completed bool   `json:"completed,omitempty"`
+}
+
+// TodoReader reads the todos to import from a file in one format, in the
+// order they should be added.
+type TodoReader interface {
+	Read(r io.Reader) ([]ImportedTodo, error)
+}
+
+var todoReaders = map[string]TodoReader{
+	"csv":      csvReader{},
+	"todotxt":  todoTxtReader{},
+	"markdown": markdownReader{},
+}
+
+var importExtensions = map[string]string{
+	".csv":      "csv",
+	".txt":      "todotxt",
+	".md":       "markdown",
+	".markdown": "markdown",
+}
+
+func importFormats() []string {
+	formats := make([]string, 0, len(todoReaders))
+	for format := range todoReaders {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+var (
+	csvTaskColumn      string
+	csvLaterColumn     string
+	csvCompletedColumn string
+	csvHeader          bool
+)
+
+// csvReader reads one todo per row. Columns are named by their header or by
+// their 1-based number.
+type csvReader struct{}
+
+func (csvReader) Read(r io.Reader) ([]ImportedTodo, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var header []string
+	if csvHeader && len(records) > 0 {
+		header, records = records[0], records[1:]
+	}
+	task, err := csvColumn(header, csvTaskColumn)
+	if err != nil {
+		return nil, err
+	}
+	later, err := csvColumn(header, csvLaterColumn)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := csvColumn(header, csvCompletedColumn)
+	if err != nil {
+		return nil, err
+	}
+	todos := make([]ImportedTodo, 0, len(records))
+	for _, record := range records {
+		todo := ImportedTodo{
+			Task:      strings.TrimSpace(csvField(record, task)),
+			Later:     isTruthy(csvField(record, later)),
+			Completed: isTruthy(csvField(record, completed)),
+		}
+		if todo.Task != "" {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+// csvColumn finds the index of a column, or -1 when none is named.
+func csvColumn(header []string, column string) (int, error) {
+	if column == "" {
+		return -1, nil
+	}
+	if number, err := strconv.Atoi(column); err == nil && number > 0 {
+		return number - 1, nil
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no column %q in the CSV header", column)
+}
+
+func csvField(record []string, column int) string {
+	if column < 0 || column >= len(record) {
+		return ""
+	}
+	return record[column]
+}
+
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "x", "y", "yes", "true", "1", "done", "completed":
+		return true
+	}
+	return false
+}
+
+var (
+	todoTxtDate     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} `)
+	todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\) `)
+)
+
+// todoTxtReader reads the todo.txt format. Lines starting with "x " are
+// completed and todos are ordered by priority, those without one last.
+type todoTxtReader struct{}
+
+func (todoTxtReader) Read(r io.Reader) ([]ImportedTodo, error) {
+	type prioritized struct {
+		todo     ImportedTodo
+		priority string
+	}
+	lines := make([]prioritized, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry prioritized
+		if strings.HasPrefix(line, "x ") {
+			entry.todo.Completed = true
+			line = strings.TrimPrefix(line, "x ")
+			// A completed todo starts with its completion date and then
+			// its creation date.
+			line = todoTxtDate.ReplaceAllString(line, "")
+			line = todoTxtDate.ReplaceAllString(line, "")
+		}
+		if match := todoTxtPriority.FindStringSubmatch(line); match != nil {
+			entry.priority = match[1]
+			line = line[len(match[0]):]
+		}
+		entry.todo.Task = strings.TrimSpace(todoTxtDate.ReplaceAllString(line, ""))
+		if entry.todo.Task != "" {
+			lines = append(lines, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].priority == "" || lines[j].priority == "" {
+			return lines[j].priority == "" && lines[i].priority != ""
+		}
+		return lines[i].priority < lines[j].priority
+	})
+	todos := make([]ImportedTodo, 0, len(lines))
+	for _, entry := range lines {
+		todos = append(todos, entry.todo)
+	}
+	return todos, nil
+}
+
+var markdownCheckbox = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.+)$`)
+
+// markdownReader reads the "- [ ]" and "- [x]" items of a Markdown checklist
+// and ignores every other line.
+type markdownReader struct{}
+
+func (markdownReader) Read(r io.Reader) ([]ImportedTodo, error) {
+	todos := make([]ImportedTodo, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		match := markdownCheckbox.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		todos = append(todos, ImportedTodo{Task: strings.TrimSpace(match[2]), Completed: match[1] != " "})
+	}
+	return todos, scanner.Err()
+}
@@ -0,0 +1,204 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep selects children of a node by name, by index or, for a
+// wildcard, all of them. Recursive steps apply to the node and every one of
+// its descendants.
+type jsonPathStep struct {
+	recursive bool
+	wildcard  bool
+	name      string
+	index     *int
+}
+
+// jsonPathRenderer prints the parts of a result's JSON form selected by a
+// JSONPath expression, one per line. Strings are printed as is and every
+// other value as JSON.
+type jsonPathRenderer struct {
+	steps []jsonPathStep
+}
+
+// newJSONPathRenderer parses expressions made of $, .name, ['name'], [n],
+// [*], .* and the recursive descent forms ..name and ..*.
+func newJSONPathRenderer(expression string) (jsonPathRenderer, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return jsonPathRenderer{}, fmt.Errorf("invalid jsonpath %q: must start with $", expression)
+	}
+	steps := make([]jsonPathStep, 0)
+	rest := expression[1:]
+	for rest != "" {
+		step := jsonPathStep{}
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			rest = parseJSONPathName(rest, &step)
+			steps = append(steps, step)
+			continue
+		case strings.HasPrefix(rest, "."):
+			rest = parseJSONPathName(rest[1:], &step)
+			steps = append(steps, step)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return jsonPathRenderer{}, fmt.Errorf("invalid jsonpath %q: unexpected %q", expression, rest)
+		}
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return jsonPathRenderer{}, fmt.Errorf("invalid jsonpath %q: missing ]", expression)
+		}
+		selector := rest[1:end]
+		rest = rest[end+1:]
+		switch {
+		case selector == "*":
+			step.wildcard = true
+		case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+			step.name = selector[1 : len(selector)-1]
+		default:
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return jsonPathRenderer{}, fmt.Errorf("invalid jsonpath %q: bad selector [%s]", expression, selector)
+			}
+			step.index = &index
+		}
+		steps = append(steps, step)
+	}
+	return jsonPathRenderer{steps: steps}, nil
+}
+
+func parseJSONPathName(rest string, step *jsonPathStep) string {
+	end := strings.IndexAny(rest, ".[")
+	if end < 0 {
+		end = len(rest)
+	}
+	if rest[:end] == "*" {
+		step.wildcard = true
+	} else {
+		step.name = rest[:end]
+	}
+	return rest[end:]
+}
+
+func (renderer jsonPathRenderer) Render(w io.Writer, result Result) error {
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	var document interface{}
+	err = json.Unmarshal(jsonData, &document)
+	if err != nil {
+		return err
+	}
+	for _, value := range renderer.evaluate(document) {
+		if text, ok := value.(string); ok {
+			_, err = fmt.Fprintln(w, text)
+		} else {
+			jsonData, _ = json.Marshal(value)
+			_, err = fmt.Fprintln(w, string(jsonData))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (renderer jsonPathRenderer) evaluate(document interface{}) []interface{} {
+	nodes := []interface{}{document}
+	for _, step := range renderer.steps {
+		candidates := nodes
+		if step.recursive {
+			candidates = make([]interface{}, 0)
+			for _, node := range nodes {
+				candidates = appendDescendants(candidates, node)
+			}
+		}
+		nodes = make([]interface{}, 0)
+		for _, node := range candidates {
+			nodes = append(nodes, step.children(node)...)
+		}
+	}
+	return nodes
+}
+
+func (step jsonPathStep) children(node interface{}) []interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if step.wildcard {
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			children := make([]interface{}, 0, len(keys))
+			for _, key := range keys {
+				children = append(children, value[key])
+			}
+			return children
+		}
+		if child, ok := value[step.name]; ok && step.index == nil {
+			return []interface{}{child}
+		}
+	case []interface{}:
+		if step.wildcard {
+			return value
+		}
+		if step.index != nil {
+			index := *step.index
+			if index < 0 {
+				index += len(value)
+			}
+			if index >= 0 && index < len(value) {
+				return []interface{}{value[index]}
+			}
+		}
+	}
+	return nil
+}
+
+// appendDescendants appends the node and all of its descendants in document
+// order.
+func appendDescendants(nodes []interface{}, node interface{}) []interface{} {
+	nodes = append(nodes, node)
+	switch value := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			nodes = appendDescendants(nodes, value[key])
+		}
+	case []interface{}:
+		for _, child := range value {
+			nodes = appendDescendants(nodes, child)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,51 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var templateExpression = regexp.MustCompile(`\{([?&]?)([^}]*)\}`)
+
+// expandLink fills in the variables of a templated link. Simple {var}
+// expressions and {?var,...} query expressions are supported; variables
+// without a value are left out.
+func expandLink(link Link, values map[string]string) Link {
+	if !link.Templated {
+		return link
+	}
+	href := templateExpression.ReplaceAllStringFunc(link.Href, func(expression string) string {
+		parts := templateExpression.FindStringSubmatch(expression)
+		operator, names := parts[1], strings.Split(parts[2], ",")
+		if operator == "" {
+			return url.PathEscape(values[names[0]])
+		}
+		pairs := make([]string, 0, len(names))
+		for _, name := range names {
+			if value, ok := values[name]; ok {
+				pairs = append(pairs, url.QueryEscape(name)+"="+url.QueryEscape(value))
+			}
+		}
+		if len(pairs) == 0 {
+			return ""
+		}
+		return operator + strings.Join(pairs, "&")
+	})
+	return Link{Href: href}
+}
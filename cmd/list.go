@@ -0,0 +1,109 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the todos of the list",
+	Long: `Show the now and later todos of the list, numbered the way complete and
+move refer to them. When the server cannot be reached, or with --offline, the
+copy of the list kept from the last time it was fetched is shown instead.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		name := selectedListName()
+		if queued, err := readQueue(); err == nil && len(queued) > 0 {
+			logger.Warn("operations are waiting to be synced", "count", len(queued))
+		}
+		if !offline {
+			listResponse, err := fetchList(ctx, name)
+			if err == nil {
+				saveSnapshot(name, listResponse.List)
+				render(ListResult{List: listResponse.List})
+				return
+			}
+			if !isUnreachable(err) {
+				logger.Error("fetching list", "error", err)
+				return
+			}
+			logger.Warn("server unreachable, showing the offline copy", "error", err)
+		}
+		mirrored, ok := loadMirror().Lists[name]
+		if !ok {
+			logger.Error("no offline copy of the list, run a command against it while online first")
+			return
+		}
+		saveSnapshot(name, mirrored.List.List)
+		render(ListResult{List: mirrored.List.List, FetchedAt: &mirrored.FetchedAt})
+	},
+}
+
+// ListResult is a list with its now and later todos. FetchedAt is set when
+// the list is the offline copy, to tell how old it is.
+type ListResult struct {
+	List      List       `json:"list"`
+	FetchedAt *time.Time `json:"fetchedAt,omitempty"`
+}
+
+func (result ListResult) String() string {
+	return result.text(terminal{})
+}
+
+func (result ListResult) text(t terminal) string {
+	var builder strings.Builder
+	if result.FetchedAt != nil {
+		fmt.Fprintln(&builder, t.paint(colorGray, "offline copy from "+result.FetchedAt.Local().Format("2006-01-02 15:04")))
+	}
+	listColors := []string{colorGreen, colorBlue}
+	for i, section := range [][]Todo{result.List.Todos, result.List.DeferredTodos} {
+		fmt.Fprintf(&builder, "%s:\n", t.paint(listColors[i], sectionName(result.List, i == 1)))
+		for number, todo := range section {
+			prefix := fmt.Sprintf("%3d. ", number+1)
+			fmt.Fprintf(&builder, "%s%s\n", prefix, t.wrap(todo.Task, len(prefix)))
+		}
+	}
+	return builder.String()
+}
+
+func (result ListResult) Columns() []string {
+	return []string{"list", "number", "task"}
+}
+
+func (result ListResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.List.Todos)+len(result.List.DeferredTodos))
+	for i, section := range [][]Todo{result.List.Todos, result.List.DeferredTodos} {
+		for number, todo := range section {
+			rows = append(rows, []string{sectionName(result.List, i == 1), strconv.Itoa(number + 1), todo.Task})
+		}
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	addListFlag(listCmd)
+}
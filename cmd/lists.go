@@ -0,0 +1,82 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// listsCmd represents the lists command
+var listsCmd = &cobra.Command{
+	Use:   "lists",
+	Short: "Show all lists",
+	Long: `Show the names of all lists, marking the current list with an asterisk.
+Use the subcommands to create, rename and switch between lists.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		rootResources := getRootResources(ctx, Link{Href: viper.GetString("root-href")})
+		render(ListsResult{
+			Current: viper.GetString("current-list"),
+			Lists:   getLists(ctx, rootResources).Lists,
+		})
+	},
+}
+
+// ListsResult is every list along with the name of the current one.
+type ListsResult struct {
+	Current string        `json:"current"`
+	Lists   []ListSummary `json:"lists"`
+}
+
+func (result ListsResult) String() string {
+	return result.text(terminal{})
+}
+
+func (result ListsResult) text(t terminal) string {
+	var builder strings.Builder
+	for _, row := range result.Rows() {
+		if row[0] == "*" {
+			fmt.Fprintf(&builder, "%s %s\n", row[0], t.paint(colorBold, row[1]))
+		} else {
+			fmt.Fprintf(&builder, "%s %s\n", row[0], row[1])
+		}
+	}
+	return builder.String()
+}
+
+func (result ListsResult) Columns() []string {
+	return []string{"current", "name"}
+}
+
+func (result ListsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Lists))
+	for _, summary := range result.Lists {
+		marker := " "
+		if summary.Name == result.Current {
+			marker = "*"
+		}
+		rows = append(rows, []string{marker, summary.Name})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(listsCmd)
+}
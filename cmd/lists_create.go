@@ -0,0 +1,69 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// listsCreateCmd represents the lists create command
+var listsCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a new list",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		if err := createList(ctx, args[0]); err != nil {
+			var notApplicableErr *notApplicableError
+			if errors.As(err, &notApplicableErr) {
+				logger.Error(err.Error())
+			} else {
+				logger.Error("request failed", "error", err)
+			}
+			return
+		}
+		render(MessageResult{Message: fmt.Sprintf("Created list %q", args[0])})
+	},
+}
+
+// createList creates a list by following the create link of the lists
+// resource.
+func createList(ctx context.Context, name string) error {
+	var rootResources ResourcesResponse
+	if err := fetchResource(ctx, "GET", Link{Href: viper.GetString("root-href")}, nil, &rootResources); err != nil {
+		return err
+	}
+	var listsResponse ListsResponse
+	if err := fetchResource(ctx, "GET", rootResources.Links["lists"], nil, &listsResponse); err != nil {
+		return err
+	}
+	link, ok := listsResponse.Links["create"]
+	if !ok {
+		return notApplicable("creating lists is not available")
+	}
+	form := make(map[string]interface{})
+	form["name"] = name
+	return fetchResource(ctx, "POST", link, form, nil)
+}
+
+func init() {
+	listsCmd.AddCommand(listsCreateCmd)
+}
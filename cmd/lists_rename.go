@@ -0,0 +1,62 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// listsRenameCmd represents the lists rename command
+var listsRenameCmd = &cobra.Command{
+	Use:   "rename <name> <new name>",
+	Short: "Rename a list",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		rootResources := getRootResources(ctx, Link{Href: viper.GetString("root-href")})
+		summary, ok := findList(ctx, rootResources, args[0])
+		if !ok {
+			logger.Error("no such list", "name", args[0])
+			return
+		}
+		link, ok := summary.Links["rename"]
+		if !ok {
+			logger.Error("list cannot be renamed", "name", args[0])
+			return
+		}
+		form := make(map[string]interface{})
+		form["name"] = args[1]
+		if err := fetchResource(ctx, "PUT", link, form, nil); err != nil {
+			logger.Error("request failed", "error", err)
+			return
+		}
+		if viper.GetString("current-list") == args[0] {
+			viper.Set("current-list", args[1])
+			err := writeConfig()
+			if err != nil {
+				logger.Error("writing config", "error", err)
+			}
+		}
+		render(MessageResult{Message: fmt.Sprintf("Renamed list %q to %q", args[0], args[1])})
+	},
+}
+
+func init() {
+	listsCmd.AddCommand(listsRenameCmd)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// listsUseCmd represents the lists use command
+var listsUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the current list",
+	Long: `Set the list that todo commands work with when --list is not given. The
+choice is stored in the config file, so each config file, which is what a
+profile is, keeps its own current list.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		rootResources := getRootResources(ctx, Link{Href: viper.GetString("root-href")})
+		if _, ok := findList(ctx, rootResources, args[0]); !ok {
+			logger.Error("no such list", "name", args[0])
+			return
+		}
+		viper.Set("current-list", args[0])
+		err := writeConfig()
+		if err != nil {
+			logger.Error("writing config", "error", err)
+		}
+		render(MessageResult{Message: fmt.Sprintf("Using list %q", args[0])})
+	},
+}
+
+func init() {
+	listsCmd.AddCommand(listsUseCmd)
+}
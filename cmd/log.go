@@ -0,0 +1,68 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+var (
+	quiet     bool
+	verbosity int
+	logFormat string
+	logFile   string
+)
+
+// logger writes diagnostics, keeping them apart from the results on stdout.
+// Until the flags are read it logs warnings and errors to stderr.
+var logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+// configureLogger sets up the logger from the -q, -v, --log-format and
+// --log-file flags. Warnings and errors are logged by default, -q limits
+// logging to errors, -v adds informational messages and -vv debug messages.
+func configureLogger() {
+	level := slog.LevelWarn
+	switch {
+	case quiet:
+		level = slog.LevelError
+	case verbosity == 1:
+		level = slog.LevelInfo
+	case verbosity > 1:
+		level = slog.LevelDebug
+	}
+	var writer io.Writer = os.Stderr
+	if logFile != "" {
+		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error opening log file:", err)
+			os.Exit(1)
+		}
+		writer = file
+	}
+	options := &slog.HandlerOptions{Level: level}
+	switch logFormat {
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(writer, options))
+	case "text":
+		logger = slog.New(slog.NewTextHandler(writer, options))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown log format %q, expected text or json\n", logFormat)
+		os.Exit(1)
+	}
+}
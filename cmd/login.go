@@ -18,9 +18,10 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
-	"net/http"
 	"fmt"
+	"net/http"
 	"bufio"
+	"context"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -38,36 +39,62 @@ Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		resourcesResponse := getBaseResources(Link{Href: viper.GetString("server-url") + "/v1/"})
+		ctx := cmd.Context()
+		resourcesResponse := getBaseResources(ctx, Link{Href: apiBaseURL() + "/v1/"})
 		scanner := bufio.NewScanner(os.Stdin)
-		login(scanner, resourcesResponse.Links["login"].Href)
+		login(ctx, scanner, resourcesResponse.Links["login"].Href)
 	},
 }
 
-func login(scanner *bufio.Scanner, url string) {
+func login(ctx context.Context, scanner *bufio.Scanner, url string) {
 	form := make(map[string]interface{})
-	fmt.Print("Email: ")
+	fmt.Fprint(promptWriter(), "Email: ")
 	scanner.Scan()
 	emailResult := scanner.Text()
 	form["email"] = emailResult
-	fmt.Print("Password: ")
+	fmt.Fprint(promptWriter(), "Password: ")
 	scanner.Scan()
 	passwordResult := scanner.Text()
 	form["password"] = passwordResult
-	httpClient := &http.Client{}
 	jsonData, _ := json.Marshal(form)
-	response, _ := httpClient.Post(url, "application/json", bytes.NewReader(jsonData))
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+	req.Header.Add("Content-Type", "application/json")
+	response, err := httpClient().Do(req)
+	if err != nil {
+		logger.Error("request failed", "method", "POST", "url", url, "error", err)
+		return
+	}
+	defer response.Body.Close()
 	var SessionResponse SessionResponse
 	jsonParseErr := json.NewDecoder(response.Body).Decode(&SessionResponse)
 	if jsonParseErr != nil {
-		fmt.Println(jsonParseErr)
+		logger.Error("decoding session response", "url", url, "error", jsonParseErr)
 	}
 	viper.Set("session-token", SessionResponse.Session.Token)
 	viper.Set("root-href", SessionResponse.Links["root"].Href)
-	err := viper.WriteConfig()
+	err = writeConfig()
 	if err != nil {
-		fmt.Println(err)
+		logger.Error("writing config", "error", err)
 	}
+	render(SessionResult{RootHref: SessionResponse.Links["root"].Href})
+}
+
+// SessionResult is the outcome of logging in or signing up. It has no text
+// form since a successful login needs no confirmation.
+type SessionResult struct {
+	RootHref string `json:"rootHref"`
+}
+
+func (result SessionResult) String() string {
+	return ""
+}
+
+func (result SessionResult) Columns() []string {
+	return []string{"rootHref"}
+}
+
+func (result SessionResult) Rows() [][]string {
+	return [][]string{{result.RootHref}}
 }
 
 func init() {
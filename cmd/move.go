@@ -0,0 +1,62 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// moveCmd represents the move command
+var moveCmd = &cobra.Command{
+	Use:   "move <number> <position>",
+	Short: "Move a todo to another position",
+	Long: `Move the todo with the given number to a new position in the now list, or
+in the later list with --later, and show how the list changed. Numbers and
+positions are the ones shown by the list command. Offline, the move is queued
+until sync.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		number, err := strconv.Atoi(args[0])
+		if err != nil {
+			logger.Error(fmt.Sprintf("invalid todo number %q", args[0]))
+			return
+		}
+		position, err := strconv.Atoi(args[1])
+		if err != nil || position < 1 {
+			logger.Error(fmt.Sprintf("invalid position %q", args[1]))
+			return
+		}
+		runOperation(ctx, func(list List) (QueuedOperation, error) {
+			todo, err := numberedTodo(list, laterTodos, number)
+			if err != nil {
+				return QueuedOperation{}, err
+			}
+			return QueuedOperation{Action: "move", Later: laterTodos, Task: todo.Task, Position: position}, nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+
+	addListFlag(moveCmd)
+	addLaterFlag(moveCmd)
+	addStrategyFlag(moveCmd)
+}
@@ -0,0 +1,487 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var offline bool
+
+// mirror is a copy of each list, keyed by the name it was selected with,
+// empty for the default list. The mirror keeps the last fetched copies and the
+// snapshots the last shown ones.
+type mirror struct {
+	Lists map[string]mirroredList `json:"lists"`
+}
+
+type mirroredList struct {
+	List      ListResponse `json:"list"`
+	FetchedAt time.Time    `json:"fetchedAt"`
+}
+
+// QueuedOperation is a change made to a list while offline, waiting to be
+// sent to the server by sync. Todos are identified by their task. Base is
+// the tasks of the list the change was made against, as it was shown.
+type QueuedOperation struct {
+	Action   string    `json:"action"`
+	List     string    `json:"list,omitempty"`
+	Later    bool      `json:"later,omitempty"`
+	Task     string    `json:"task"`
+	NewTask  string    `json:"newTask,omitempty"`
+	Position int       `json:"position,omitempty"`
+	Base     []string  `json:"base"`
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+func (operation QueuedOperation) String() string {
+	description := fmt.Sprintf("%s %q", operation.Action, operation.Task)
+	switch operation.Action {
+	case "move":
+		description += fmt.Sprintf(" to %d", operation.Position)
+	case "update":
+		description += fmt.Sprintf(" to %q", operation.NewTask)
+	}
+	if operation.Later {
+		description += " (later)"
+	}
+	if operation.List != "" {
+		description += fmt.Sprintf(" in list %q", operation.List)
+	}
+	return description
+}
+
+// notApplicableError is returned for an operation that the list it is made
+// to no longer allows, such as completing a todo that is gone.
+type notApplicableError struct {
+	reason string
+}
+
+func (err *notApplicableError) Error() string {
+	return err.reason
+}
+
+func notApplicable(format string, args ...interface{}) error {
+	return &notApplicableError{reason: fmt.Sprintf(format, args...)}
+}
+
+// isUnreachable tells whether a request failed because the server could not
+// be reached, its connection refused, its name not found or the request timed
+// out, or because it answered that it is unavailable. A refused certificate,
+// a request missing from a cassette or an interrupt is not a reason to work
+// offline.
+func isUnreachable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return retryableStatuses[statusErr.code]
+	}
+	if err == nil || errors.Is(err, context.Canceled) || isCertificateError(err) {
+		return false
+	}
+	var notRecorded *notRecordedError
+	if errors.As(err, &notRecorded) {
+		return false
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr) ||
+		(errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// isCertificateError tells whether the server's certificate was refused,
+// which working offline would only hide.
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid)
+}
+
+// runOperation builds an operation against the selected list as it was last
+// shown and applies it on the server. When the server cannot be reached, or
+// earlier operations are still waiting to be synced, the operation is queued
+// instead and applied to the mirrored list.
+func runOperation(ctx context.Context, build func(list List) (QueuedOperation, error)) {
+	name := selectedListName()
+	if err := checkStrategy(); err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	queued, err := readQueue()
+	if err != nil {
+		logger.Error("reading operation queue", "error", err)
+		return
+	}
+	if !offline && len(queued) == 0 {
+		err := runOnline(ctx, name, build)
+		if !isUnreachable(err) {
+			if err != nil {
+				logger.Error(err.Error())
+			}
+			return
+		}
+		logger.Warn("server unreachable, working offline", "error", err)
+	}
+	mirrored, ok := loadMirror().Lists[name]
+	if !ok {
+		logger.Error("no offline copy of the list, run a command against it while online first")
+		return
+	}
+	before := mirrored.List.List
+	view := viewedList(name, before)
+	operation, err := build(view)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	operation.Base = sectionTasks(view, operation.Later)
+	operation.List = name
+	operation.QueuedAt = time.Now().UTC()
+	after, err := applyOperation(before, operation)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	err = appendQueue(operation)
+	if err != nil {
+		logger.Error("queueing operation", "error", err)
+		return
+	}
+	mirrored.List.List = after
+	saveMirroredList(name, mirrored.List)
+	saveSnapshot(name, after)
+	logger.Warn("queued until the next sync", "operation", operation.String())
+	render(listDiff(operation.Action, before, after))
+}
+
+// runOnline applies an operation on the server and renders what it changed.
+func runOnline(ctx context.Context, name string, build func(list List) (QueuedOperation, error)) error {
+	before, err := fetchList(ctx, name)
+	if err != nil {
+		return err
+	}
+	view := viewedList(name, before.List)
+	operation, err := build(view)
+	if err != nil {
+		return err
+	}
+	operation.Base = sectionTasks(view, operation.Later)
+	if found := detectConflict(operation, before.List); found != nil {
+		switch resolveConflict(found) {
+		case strategyTheirs:
+			render(MessageResult{Message: "Kept the server's version, nothing was changed"})
+			return nil
+		case strategyAbort:
+			render(MessageResult{Message: "Aborted, nothing was changed"})
+			return nil
+		}
+	}
+	before, err = sendListMutation(ctx, name, before, func(list List) error {
+		return sendOperation(ctx, list, operation)
+	})
+	if err == errNotRetried {
+		render(MessageResult{Message: "Nothing was changed"})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// The operation has been made, so a failure from here on must not lead
+	// to it being queued a second time.
+	after, err := fetchList(ctx, name)
+	if err != nil {
+		logger.Error("fetching list", "error", err)
+	}
+	saveSnapshot(name, after.List)
+	render(listDiff(operation.Action, before.List, after.List))
+	return nil
+}
+
+// sendOperation applies an operation to a list on the server by following
+// the list's or the todo's links.
+func sendOperation(ctx context.Context, list List, operation QueuedOperation) error {
+	switch operation.Action {
+	case "add":
+		rel := "create"
+		if operation.Later {
+			rel = "createDeferred"
+		}
+		link, ok := list.Links[rel]
+		if !ok {
+			return notApplicable("adding todos is not available")
+		}
+		form := make(map[string]interface{})
+		form["task"] = operation.Task
+		return fetchResource(ctx, "POST", link, form, nil)
+	case "complete", "move", "update", "delete":
+		todo, _, ok := findTodo(list, operation.Later, operation.Task)
+		if !ok {
+			return notApplicable("no todo %q in the %s list", operation.Task, sectionName(list, operation.Later))
+		}
+		return sendTodoOperation(ctx, list, todo, operation)
+	}
+	return notApplicable("unknown operation %q", operation.Action)
+}
+
+// sendTodoOperation completes, moves, updates or deletes a todo of a list by
+// following the todo's link for the operation.
+func sendTodoOperation(ctx context.Context, list List, todo Todo, operation QueuedOperation) error {
+	link, ok := todo.Links[operation.Action]
+	if !ok {
+		return notApplicable("todo %q cannot be %sd", operation.Task, operation.Action)
+	}
+	method := "POST"
+	var form map[string]interface{}
+	switch operation.Action {
+	case "move":
+		form = map[string]interface{}{"position": targetPosition(operation, list)}
+	case "update":
+		method = "PUT"
+		form = map[string]interface{}{"task": operation.NewTask}
+	case "delete":
+		method = "DELETE"
+	}
+	return fetchResource(ctx, method, link, form, nil)
+}
+
+// applyOperation makes the change an operation describes to a copy of a
+// list, the way the server would.
+func applyOperation(list List, operation QueuedOperation) (List, error) {
+	todos := append([]Todo{}, list.Todos...)
+	if operation.Later {
+		todos = append([]Todo{}, list.DeferredTodos...)
+	}
+	switch operation.Action {
+	case "add":
+		todos = append(todos, Todo{Task: operation.Task})
+	case "update":
+		_, index, ok := findTodo(list, operation.Later, operation.Task)
+		if !ok {
+			return list, notApplicable("no todo %q in the %s list", operation.Task, sectionName(list, operation.Later))
+		}
+		todos[index] = Todo{Task: operation.NewTask}
+	case "complete", "move", "delete":
+		todo, index, ok := findTodo(list, operation.Later, operation.Task)
+		if !ok {
+			return list, notApplicable("no todo %q in the %s list", operation.Task, sectionName(list, operation.Later))
+		}
+		todos = append(todos[:index], todos[index+1:]...)
+		if operation.Action == "move" {
+			position := clampPosition(targetPosition(operation, list), len(todos)+1)
+			todos = append(todos[:position-1], append([]Todo{todo}, todos[position-1:]...)...)
+		}
+	default:
+		return list, notApplicable("unknown operation %q", operation.Action)
+	}
+	if operation.Later {
+		list.DeferredTodos = todos
+	} else {
+		list.Todos = todos
+	}
+	return list, nil
+}
+
+func clampPosition(position int, length int) int {
+	if position < 1 {
+		return 1
+	}
+	if position > length {
+		return length
+	}
+	return position
+}
+
+func findTodo(list List, later bool, task string) (Todo, int, bool) {
+	todos := list.Todos
+	if later {
+		todos = list.DeferredTodos
+	}
+	for i, todo := range todos {
+		if todo.Task == task {
+			return todo, i, true
+		}
+	}
+	return Todo{}, -1, false
+}
+
+// numberedTodo finds a todo by the 1-based number the list command shows it
+// with.
+func numberedTodo(list List, later bool, number int) (Todo, error) {
+	todos := list.Todos
+	if later {
+		todos = list.DeferredTodos
+	}
+	if number < 1 || number > len(todos) {
+		return Todo{}, fmt.Errorf("no todo number %d in the %s list", number, sectionName(list, later))
+	}
+	return todos[number-1], nil
+}
+
+func sectionName(list List, later bool) string {
+	if later {
+		return deferredName(list)
+	}
+	return nowName(list)
+}
+
+// offlineDir holds the mirror and the operation queue, in the .doer-cli
+// directory next to the config file.
+func offlineDir() string {
+	return filepath.Join(filepath.Dir(cfgFile), ".doer-cli")
+}
+
+func mirrorPath() string {
+	return filepath.Join(offlineDir(), "mirror.json")
+}
+
+func queuePath() string {
+	return filepath.Join(offlineDir(), "queue.jsonl")
+}
+
+func loadMirror() mirror {
+	return loadLists(mirrorPath())
+}
+
+func saveMirroredList(name string, listResponse ListResponse) {
+	saveList(mirrorPath(), name, listResponse)
+}
+
+// loadLists reads a file of lists kept by name, such as the mirror.
+func loadLists(path string) mirror {
+	loaded := mirror{Lists: make(map[string]mirroredList)}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return loaded
+	}
+	if err := json.Unmarshal(contents, &loaded); err != nil {
+		logger.Warn("ignoring unreadable lists", "path", path, "error", err)
+		return mirror{Lists: make(map[string]mirroredList)}
+	}
+	if loaded.Lists == nil {
+		loaded.Lists = make(map[string]mirroredList)
+	}
+	return loaded
+}
+
+func saveList(path string, name string, listResponse ListResponse) {
+	saved := loadLists(path)
+	saved.Lists[name] = mirroredList{List: listResponse, FetchedAt: time.Now().UTC()}
+	contents, err := json.MarshalIndent(saved, "", "  ")
+	if err == nil {
+		err = writeFileAtomically(path, append(contents, '\n'), 0600)
+	}
+	if err != nil {
+		logger.Warn("writing lists", "path", path, "error", err)
+	}
+}
+
+// readQueue reads the queued operations, oldest first. A last line cut short
+// by a crash while it was being appended is ignored.
+func readQueue() ([]QueuedOperation, error) {
+	operations := make([]QueuedOperation, 0)
+	contents, err := ioutil.ReadFile(queuePath())
+	if os.IsNotExist(err) {
+		return operations, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(make([]byte, 64*1024), len(contents)+1)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var operation QueuedOperation
+		if err := json.Unmarshal(line, &operation); err != nil {
+			logger.Warn("ignoring unreadable queued operation", "path", queuePath(), "error", err)
+			continue
+		}
+		operations = append(operations, operation)
+	}
+	return operations, scanner.Err()
+}
+
+// appendQueue adds an operation to the end of the queue and waits for it to
+// reach the disk.
+func appendQueue(operation QueuedOperation) error {
+	line, err := json.Marshal(operation)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(offlineDir(), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(queuePath(), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	err = dropPartialLine(file)
+	if err == nil {
+		_, err = file.Write(append(line, '\n'))
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// dropPartialLine cuts off a last line left short by a crash, so that the
+// next line is not glued onto it, and leaves the file positioned at its end.
+func dropPartialLine(file *os.File) error {
+	contents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	end := bytes.LastIndexByte(contents, '\n') + 1
+	if end < len(contents) {
+		if err := file.Truncate(int64(end)); err != nil {
+			return err
+		}
+	}
+	_, err = file.Seek(int64(end), io.SeekStart)
+	return err
+}
+
+// writeQueue replaces the queue with the given operations.
+func writeQueue(operations []QueuedOperation) error {
+	var contents bytes.Buffer
+	for _, operation := range operations {
+		line, err := json.Marshal(operation)
+		if err != nil {
+			return err
+		}
+		contents.Write(append(line, '\n'))
+	}
+	return writeFileAtomically(queuePath(), contents.Bytes(), 0600)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Result is the outcome of a command. Results are rendered as JSON or YAML
+// from their json tags, and as rows under the given columns for the table
+// and TSV formats. Results that implement fmt.Stringer use that form for
+// the default text format.
+type Result interface {
+	Columns() []string
+	Rows() [][]string
+}
+
+// Renderer writes a result in a single output format.
+type Renderer interface {
+	Render(w io.Writer, result Result) error
+}
+
+var renderers = map[string]Renderer{
+	"text":  textRenderer{},
+	"json":  jsonRenderer{},
+	"yaml":  yamlRenderer{},
+	"table": tableRenderer{},
+	"tsv":   tsvRenderer{},
+}
+
+func outputFormats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// renderer is chosen from the output flags before any command runs.
+var renderer Renderer = textRenderer{}
+
+// configureRenderer picks the renderer for --output, unless a template or
+// JSONPath expression was given, which take precedence.
+func configureRenderer() error {
+	if _, ok := renderers[outputFormat]; !ok {
+		return fmt.Errorf("unknown output format %q, expected one of %v", outputFormat, outputFormats())
+	}
+	switch {
+	case outputTemplate != "" || outputTemplateName != "":
+		if outputJSONPath != "" {
+			return fmt.Errorf("--jsonpath cannot be combined with a template")
+		}
+		templateRenderer, err := newTemplateRenderer(outputTemplate, outputTemplateName)
+		if err != nil {
+			return err
+		}
+		renderer = templateRenderer
+	case outputJSONPath != "":
+		jsonPathRenderer, err := newJSONPathRenderer(outputJSONPath)
+		if err != nil {
+			return err
+		}
+		renderer = jsonPathRenderer
+	default:
+		renderer = renderers[outputFormat]
+	}
+	return nil
+}
+
+// render writes a result to stdout with the configured renderer.
+func render(result Result) {
+	err := renderer.Render(os.Stdout, result)
+	if err != nil {
+		logger.Error("rendering result", "error", err)
+	}
+}
+
+// promptWriter is where interactive prompts go. They share stdout with the
+// results in the text format and move to stderr in every other format so
+// that stdout stays machine readable.
+func promptWriter() io.Writer {
+	if outputFormat == "text" && outputTemplate == "" && outputTemplateName == "" && outputJSONPath == "" {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+type textRenderer struct{}
+
+func (textRenderer) Render(w io.Writer, result Result) error {
+	if styled, ok := result.(styledResult); ok && w == os.Stdout {
+		_, err := io.WriteString(w, styled.text(stdoutTerminal()))
+		return err
+	}
+	if stringer, ok := result.(fmt.Stringer); ok {
+		_, err := io.WriteString(w, stringer.String())
+		return err
+	}
+	return writeRows(w, result.Rows())
+}
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, result Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+type yamlRenderer struct{}
+
+func (yamlRenderer) Render(w io.Writer, result Result) error {
+	// Round trip through JSON so YAML keys follow the documented json tags.
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	var document interface{}
+	err = yaml.Unmarshal(jsonData, &document)
+	if err != nil {
+		return err
+	}
+	yamlData, err := yaml.Marshal(document)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(yamlData)
+	return err
+}
+
+type tableRenderer struct{}
+
+func (tableRenderer) Render(w io.Writer, result Result) error {
+	header := make([]string, 0, len(result.Columns()))
+	for _, column := range result.Columns() {
+		header = append(header, strings.ToUpper(column))
+	}
+	return writeRows(w, append([][]string{header}, result.Rows()...))
+}
+
+func writeRows(w io.Writer, rows [][]string) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+type tsvRenderer struct{}
+
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+func (tsvRenderer) Render(w io.Writer, result Result) error {
+	for _, row := range append([][]string{result.Columns()}, result.Rows()...) {
+		fields := make([]string, 0, len(row))
+		for _, field := range row {
+			fields = append(fields, tsvEscaper.Replace(field))
+		}
+		_, err := fmt.Fprintln(w, strings.Join(fields, "\t"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MessageResult is a confirmation or notice that carries no other data.
+type MessageResult struct {
+	Message string `json:"message"`
+}
+
+func (result MessageResult) String() string {
+	return result.Message + "\n"
+}
+
+func (result MessageResult) Columns() []string {
+	return []string{"message"}
+}
+
+func (result MessageResult) Rows() [][]string {
+	return [][]string{{result.Message}}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const progressBarWidth = 30
+
+// progressBar shows how far a long running command has got on stderr. It
+// is only drawn when stderr is a terminal, so logs and pipes stay clean.
+type progressBar struct {
+	out     io.Writer
+	total   int
+	enabled bool
+}
+
+func newProgressBar(total int) *progressBar {
+	return &progressBar{
+		out:     os.Stderr,
+		total:   total,
+		enabled: !quiet && logFile == "" && term.IsTerminal(int(os.Stderr.Fd())),
+	}
+}
+
+func (p *progressBar) set(done int) {
+	if !p.enabled || p.total == 0 {
+		return
+	}
+	filled := progressBarWidth * done / p.total
+	fmt.Fprintf(p.out, "\r[%s%s] %d/%d", strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), done, p.total)
+}
+
+// finish ends the line the bar is drawn on.
+func (p *progressBar) finish() {
+	if p.enabled && p.total > 0 {
+		fmt.Fprintln(p.out)
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// redoCmd represents the redo command
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Redo the last undone change to the list",
+	Long: `Redo the last change to the list that was reverted with undo, and show
+how the list looked before and after.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		followListAction(ctx, "redo")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(redoCmd)
+
+	addListFlag(redoCmd)
+}
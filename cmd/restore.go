@@ -0,0 +1,209 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore the lists in a backup archive",
+	Long: `Recreate the lists in an archive made with backup, on this server and
+account or another. Lists that do not exist yet are created. The archive is
+checked against its manifest before anything is changed.
+
+Todos a list already has are not added again, so a restore that failed part
+way can be run again. Completed todos are added and completed, which gives
+them the time of the restore as their completion time. A list without a
+completed history on the server gets none of its completed todos back, as
+there is no telling which of them an earlier restore added.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manifest, files, err := readArchive(args[0])
+		if err != nil {
+			return err
+		}
+		exports := make([]Export, 0, len(manifest.Lists))
+		total := 0
+		for _, entry := range manifest.Lists {
+			var export Export
+			if err := json.Unmarshal(files[entry.File], &export); err != nil {
+				return fmt.Errorf("reading %s from backup archive: %v", entry.File, err)
+			}
+			exports = append(exports, export)
+			total += len(export.Todos) + len(export.DeferredTodos) + len(export.Completed)
+		}
+		result := RestoreResult{Lists: make([]RestoredList, 0, len(exports))}
+		progress := newProgressBar(total)
+		done := 0
+		for i, entry := range manifest.Lists {
+			restored, err := restoreList(ctx, entry.Name, exports[i], func() {
+				done++
+				progress.set(done)
+			})
+			restored.Name = entry.displayName()
+			result.Lists = append(result.Lists, restored)
+			if err != nil {
+				progress.finish()
+				render(result)
+				return fmt.Errorf("restore stopped at the %s list, run it again to continue: %v", restored.Name, err)
+			}
+		}
+		progress.finish()
+		render(result)
+		return nil
+	},
+}
+
+// restoreList adds the todos of an export that the named list does not have
+// yet, creating the list first when there is none by that name.
+func restoreList(ctx context.Context, name string, export Export, step func()) (RestoredList, error) {
+	var restored RestoredList
+	target, err := fetchList(ctx, name)
+	var notApplicableErr *notApplicableError
+	if name != "" && errors.As(err, &notApplicableErr) {
+		if err := createList(ctx, name); err != nil {
+			return restored, err
+		}
+		target, err = fetchList(ctx, name)
+	}
+	if err != nil {
+		return restored, err
+	}
+	restore := func(tasks []string, existing []string, later bool, completed bool) error {
+		remaining := make(map[string]int)
+		for _, task := range existing {
+			remaining[task]++
+		}
+		for _, task := range tasks {
+			if remaining[task] > 0 {
+				remaining[task]--
+				restored.Skipped++
+				step()
+				continue
+			}
+			if err := restoreTodo(ctx, name, ImportedTodo{Task: task, Later: later, Completed: completed}); err != nil {
+				return err
+			}
+			if completed {
+				restored.Completed++
+			} else {
+				restored.Added++
+			}
+			step()
+		}
+		return nil
+	}
+	if err := restore(export.Todos, tasks(target.List.Todos), false, false); err != nil {
+		return restored, err
+	}
+	if err := restore(export.DeferredTodos, tasks(target.List.DeferredTodos), true, false); err != nil {
+		return restored, err
+	}
+	if len(export.Completed) == 0 {
+		return restored, nil
+	}
+	link, ok := target.List.Links["completed"]
+	if !ok && name == "" {
+		var rootResources ResourcesResponse
+		if err := fetchResource(ctx, "GET", Link{Href: viper.GetString("root-href")}, nil, &rootResources); err != nil {
+			return restored, err
+		}
+		link = rootResources.Links["completedList"]
+	}
+	if link.Href == "" {
+		// Without a completed history there is no telling which completed
+		// todos an earlier restore already added, so none are added.
+		logger.Warn("list has no completed history, leaving out its completed todos", "list", name, "todos", len(export.Completed))
+		for range export.Completed {
+			step()
+		}
+		return restored, nil
+	}
+	existing, err := fetchCompletedTodos(ctx, link, time.Time{}, time.Time{})
+	if err != nil {
+		return restored, err
+	}
+	return restored, restore(completedTasks(export.Completed), completedTasks(existing), false, true)
+}
+
+// restoreTodo adds a todo to the named list, completing it right away when
+// it was completed.
+func restoreTodo(ctx context.Context, name string, todo ImportedTodo) error {
+	list, err := fetchList(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := sendOperation(ctx, list.List, QueuedOperation{Action: "add", Later: todo.Later, Task: todo.Task}); err != nil {
+		return err
+	}
+	if !todo.Completed {
+		return nil
+	}
+	list, err = fetchList(ctx, name)
+	if err != nil {
+		return err
+	}
+	return completeLastTodo(ctx, list.List, todo)
+}
+
+// RestoreResult is how many todos were added to each list, how many were
+// completed, and how many the list already had.
+type RestoreResult struct {
+	Lists []RestoredList `json:"lists"`
+}
+
+type RestoredList struct {
+	Name      string `json:"name"`
+	Added     int    `json:"added"`
+	Completed int    `json:"completed"`
+	Skipped   int    `json:"skipped"`
+}
+
+func (result RestoreResult) String() string {
+	var builder strings.Builder
+	for _, restored := range result.Lists {
+		fmt.Fprintf(&builder, "%s: %d added, %d completed, %d already there\n", restored.Name, restored.Added, restored.Completed, restored.Skipped)
+	}
+	return builder.String()
+}
+
+func (result RestoreResult) Columns() []string {
+	return []string{"list", "added", "completed", "skipped"}
+}
+
+func (result RestoreResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Lists))
+	for _, restored := range result.Lists {
+		rows = append(rows, []string{restored.Name, fmt.Sprint(restored.Added), fmt.Sprint(restored.Completed), fmt.Sprint(restored.Skipped)})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const maxRetryDelay = time.Minute
+
+var retryableStatuses = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// retryTransport retries idempotent requests that fail to connect or that
+// the server asks to be retried, waiting with exponential backoff and jitter
+// or for as long as a Retry-After header says.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	idempotent := req.Method == "GET" || req.Method == "HEAD"
+	for attempt := 0; ; attempt++ {
+		response, err := t.next.RoundTrip(req)
+		if !idempotent || attempt >= t.retries || !shouldRetry(response, err) {
+			return response, err
+		}
+		delay := t.delay(attempt, response)
+		logger.Info("retrying request", "method", req.Method, "url", req.URL.String(), "attempt", attempt+1, "delay", delay)
+		if response != nil {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func shouldRetry(response *http.Response, err error) bool {
+	var notRecorded *notRecordedError
+	if errors.As(err, &notRecorded) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return retryableStatuses[response.StatusCode]
+}
+
+// delay waits for the Retry-After the server sent, or otherwise for a random
+// time between half and all of the backoff doubled for each attempt.
+func (t *retryTransport) delay(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			if retryAfter > maxRetryDelay {
+				return maxRetryDelay
+			}
+			return retryAfter
+		}
+	}
+	backoff := t.backoff << uint(attempt)
+	if backoff <= 0 || backoff > maxRetryDelay {
+		backoff = maxRetryDelay
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
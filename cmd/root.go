@@ -17,11 +17,18 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"os/signal"
 	"sort"
+	"syscall"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -29,8 +36,22 @@ import (
 )
 
 var (
-	cfgFile   string
-	serverUrl string
+	cfgFile            string
+	serverUrl          string
+	listName           string
+	laterTodos         bool
+	outputFormat       string
+	outputTemplate     string
+	outputTemplateName string
+	outputJSONPath     string
+)
+
+const (
+	// interruptExitCode is the conventional status for a process stopped by
+	// SIGINT.
+	interruptExitCode = 130
+	// interruptGracePeriod is how long an interrupted command has to finish.
+	interruptGracePeriod = time.Second
 )
 
 type ResourcesResponse struct {
@@ -38,7 +59,62 @@ type ResourcesResponse struct {
 }
 
 type Link struct {
-	Href string `json:"href"`
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+type ListResponse struct {
+	List  List            `json:"list"`
+	Links map[string]Link `json:"_links"`
+}
+
+type List struct {
+	Name           string          `json:"name"`
+	DeferredName   string          `json:"deferredName"`
+	Todos          []Todo          `json:"todos"`
+	DeferredTodos  []Todo          `json:"deferredTodos"`
+	UnlockDuration int64           `json:"unlockDuration"`
+	Links          map[string]Link `json:"_links"`
+}
+
+type Todo struct {
+	Task  string          `json:"task"`
+	Links map[string]Link `json:"_links"`
+}
+
+type ListsResponse struct {
+	Lists []ListSummary   `json:"lists"`
+	Links map[string]Link `json:"_links"`
+}
+
+type ListSummary struct {
+	Name  string          `json:"name"`
+	Links map[string]Link `json:"_links"`
+}
+
+type SearchResponse struct {
+	Todos []SearchResult  `json:"todos"`
+	Links map[string]Link `json:"_links"`
+}
+
+type SearchResult struct {
+	Task     string `json:"task"`
+	List     string `json:"list"`
+	deferred bool
+}
+
+type CompletedListResponse struct {
+	List  CompletedList   `json:"list"`
+	Links map[string]Link `json:"_links"`
+}
+
+type CompletedList struct {
+	Todos []CompletedTodo `json:"todos"`
+}
+
+type CompletedTodo struct {
+	Task        string    `json:"task"`
+	CompletedAt time.Time `json:"completedAt"`
 }
 
 type SessionResponse struct {
@@ -54,20 +130,42 @@ type Session struct {
 var rootCmd = &cobra.Command{
 	Use:   "doer-cli",
 	Short: "A brief description of your application",
-	Long: `A longer description that spans multiple lines and likely contains
-examples and usage of using your application. For example:
+	Long: `Manage the todo lists of a doer server. Run without a command to choose an
+action.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+Settings are kept in a config file, $HOME/.doer-cli.yml unless --config names
+another. There are no named profiles: a profile is a config file of its own,
+with its own session, current list and connection settings such as the TLS
+options, so give each account or server its own config file, in a directory
+of its own, and select it with --config. See docs/config.md.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := applyConfigDefaults(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		err = configureHTTPClient()
+		if err != nil {
+			return err
+		}
+		return configureRenderer()
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
 		var resourcesResponse ResourcesResponse
 		if viper.IsSet("session-token") {
-			resourcesResponse = getRootResources(Link{Href: viper.GetString("root-href")})
+			resourcesResponse = getRootResources(ctx, Link{Href: viper.GetString("root-href")})
+			if listLink, ok := resourcesResponse.Links["list"]; ok {
+				var listResponse ListResponse
+				if err := fetchResource(ctx, "GET", listLink, nil, &listResponse); err != nil {
+					logger.Error("request failed", "error", err)
+				} else {
+					render(lockStatus(listResponse.List))
+				}
+			}
 		} else {
-			resourcesResponse = getBaseResources(Link{Href: serverUrl + "/v1/"})
+			resourcesResponse = getBaseResources(ctx, Link{Href: apiBaseURL() + "/v1/"})
 		}
 		action := chooseNextAction(resourcesResponse, bufio.NewScanner(os.Stdin))
 		switch action {
@@ -75,8 +173,14 @@ to quickly create a Cobra application.`,
 			loginCmd.Run(cmd, args)
 		case "signup":
 			signupCmd.Run(cmd, args)
+		case "completedList":
+			completedCmd.Run(cmd, args)
+		case "list":
+			listCmd.Run(cmd, args)
+		case "lists":
+			listsCmd.Run(cmd, args)
 		default:
-			fmt.Println("Chosen selection has not yet been implemented")
+			render(MessageResult{Message: "Chosen selection has not yet been implemented"})
 		}
 	},
 }
@@ -89,46 +193,218 @@ func chooseNextAction(resourcesResponse ResourcesResponse, scanner *bufio.Scanne
 		}
 	}
 	sort.Strings(resourceOptions)
-	fmt.Printf("Choose action %v: ", resourceOptions)
+	render(ActionsResult{Actions: resourceOptions})
 	scanner.Scan()
 	return scanner.Text()
 }
 
-func getRootResources(link Link) ResourcesResponse {
-	client := &http.Client{}
-	req, _ := http.NewRequest("GET", link.Href, nil)
-	req.Header.Add("Session-Token", viper.GetString("session-token"))
-	response, _ := client.Do(req)
+// ActionsResult lists the actions that can be chosen from a resource.
+type ActionsResult struct {
+	Actions []string `json:"actions"`
+}
+
+func (result ActionsResult) String() string {
+	return fmt.Sprintf("Choose action %v: ", result.Actions)
+}
+
+func (result ActionsResult) Columns() []string {
+	return []string{"action"}
+}
+
+func (result ActionsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Actions))
+	for _, action := range result.Actions {
+		rows = append(rows, []string{action})
+	}
+	return rows
+}
+
+func getRootResources(ctx context.Context, link Link) ResourcesResponse {
 	var resourcesResponse ResourcesResponse
-	jsonParseErr := json.NewDecoder(response.Body).Decode(&resourcesResponse)
+	getResource(ctx, link, &resourcesResponse)
+	return resourcesResponse
+}
+
+// getList fetches the list chosen with --list, falling back to the list set
+// with "lists use" and then to the default list of the root resources.
+func getList(ctx context.Context) ListResponse {
+	listResponse, err := fetchList(ctx, selectedListName())
+	var notApplicableErr *notApplicableError
+	if errors.As(err, &notApplicableErr) {
+		logger.Error(err.Error())
+	} else if err != nil {
+		logger.Error("request failed", "error", err)
+	}
+	return listResponse
+}
+
+// fetchList gets the named list, or the default list when name is empty, and
+// keeps a copy of it in the offline mirror.
+func fetchList(ctx context.Context, name string) (ListResponse, error) {
+	var listResponse ListResponse
+	var rootResources ResourcesResponse
+	err := fetchResource(ctx, "GET", Link{Href: viper.GetString("root-href")}, nil, &rootResources)
+	if err != nil {
+		return listResponse, err
+	}
+	link := rootResources.Links["list"]
+	if name != "" {
+		summary, ok := findList(ctx, rootResources, name)
+		if !ok {
+			return listResponse, notApplicable("no such list %q", name)
+		}
+		link = summary.Links["list"]
+	}
+	err = fetchResource(ctx, "GET", link, nil, &listResponse)
+	if err != nil {
+		return listResponse, err
+	}
+	saveMirroredList(name, listResponse)
+	return listResponse, nil
+}
+
+func getLists(ctx context.Context, rootResources ResourcesResponse) ListsResponse {
+	var listsResponse ListsResponse
+	getResource(ctx, rootResources.Links["lists"], &listsResponse)
+	return listsResponse
+}
+
+func findList(ctx context.Context, rootResources ResourcesResponse, name string) (ListSummary, bool) {
+	for _, summary := range getLists(ctx, rootResources).Lists {
+		if summary.Name == name {
+			return summary, true
+		}
+	}
+	return ListSummary{}, false
+}
+
+func selectedListName() string {
+	if listName != "" {
+		return listName
+	}
+	return viper.GetString("current-list")
+}
+
+// addListFlag registers the --list flag on a command that works with a list.
+func addListFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&listName, "list", "", "name of the list to use instead of the current list")
+}
+
+func addLaterFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&laterTodos, "later", false, "use the later list instead of the now list")
+}
+
+func getResource(ctx context.Context, link Link, resource interface{}) {
+	sendResource(ctx, "GET", link, nil, resource)
+}
+
+// sendResource makes an authenticated request to the link, sending body as
+// JSON when it is not nil and decoding the response into resource when it is
+// not nil.
+func sendResource(ctx context.Context, method string, link Link, body interface{}, resource interface{}) {
+	err := fetchResource(ctx, method, link, body, resource)
+	if err != nil {
+		logger.Error("request failed", "method", method, "url", link.Href, "error", err)
+	}
+}
+
+// fetchResource is sendResource for callers that handle a failed request
+// themselves, such as by working offline.
+func fetchResource(ctx context.Context, method string, link Link, body interface{}, resource interface{}) error {
+	client := httpClient()
+	var requestBody io.Reader
+	if body != nil {
+		jsonData, _ := json.Marshal(body)
+		requestBody = bytes.NewReader(jsonData)
+	}
+	req, _ := http.NewRequestWithContext(ctx, method, link.Href, requestBody)
+	req.Header.Add("Session-Token", viper.GetString("session-token"))
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	if method != "GET" && lastETag != "" {
+		req.Header.Add("If-Match", lastETag)
+	}
+	response, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	logger.Debug("request", "method", method, "url", link.Href, "status", response.StatusCode)
+	if method == "GET" {
+		rememberETag(response.Header.Get("ETag"))
+	}
+	if response.StatusCode >= 400 {
+		return &statusError{code: response.StatusCode, status: response.Status}
+	}
+	if resource == nil {
+		return nil
+	}
+	jsonParseErr := json.NewDecoder(response.Body).Decode(resource)
 	if jsonParseErr != nil {
-		fmt.Println(jsonParseErr)
+		logger.Error("decoding response", "url", link.Href, "error", jsonParseErr)
 	}
-	return resourcesResponse
+	return nil
 }
 
-func getBaseResources(link Link) ResourcesResponse {
-	client := &http.Client{}
-	response, _ := client.Get(link.Href)
+// statusError is a response from the server with an error status.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (err *statusError) Error() string {
+	return "server responded " + err.status
+}
+
+func getBaseResources(ctx context.Context, link Link) ResourcesResponse {
+	client := httpClient()
 	var resourcesResponse ResourcesResponse
+	req, _ := http.NewRequestWithContext(ctx, "GET", link.Href, nil)
+	response, err := client.Do(req)
+	if err != nil {
+		logger.Error("request failed", "method", "GET", "url", link.Href, "error", err)
+		return resourcesResponse
+	}
+	defer response.Body.Close()
 	jsonParseErr := json.NewDecoder(response.Body).Decode(&resourcesResponse)
 	if jsonParseErr != nil {
-		fmt.Println(jsonParseErr)
+		logger.Error("decoding response", "url", link.Href, "error", jsonParseErr)
 	}
 	return resourcesResponse
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// An interrupt or termination signal cancels the context every command and
+// request runs under, and the CLI exits with status 130.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		received := <-signals
+		logger.Info("interrupted", "signal", received.String())
+		cancel()
+		// A command waiting on input never sees the cancellation, so give
+		// the others a moment to unwind and then stop regardless.
+		time.Sleep(interruptGracePeriod)
+		os.Exit(interruptExitCode)
+	}()
+	err := rootCmd.ExecuteContext(ctx)
+	if ctx.Err() != nil {
+		os.Exit(interruptExitCode)
+	}
+	if err != nil {
+		logger.Error(err.Error())
 		os.Exit(1)
 	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(configureLogger, initConfig)
+	rootCmd.SilenceErrors = true
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
@@ -139,7 +415,31 @@ func init() {
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	rootCmd.PersistentFlags().StringVarP(&serverUrl, "api", "a", "http://localhost:8080", "used for setting the api target")
+	rootCmd.PersistentFlags().StringVarP(&serverUrl, "api", "a", "http://localhost:8080", "used for setting the api target, either an http(s) URL or unix:///path/to.sock")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "output format, one of text, json, yaml, table or tsv")
+	rootCmd.PersistentFlags().StringVar(&outputTemplate, "template", "", "Go template to render results with, overriding --output")
+	rootCmd.PersistentFlags().StringVar(&outputTemplateName, "template-name", "", "name of a template in the templates directory next to the config file, overriding --output")
+	rootCmd.PersistentFlags().StringVar(&outputJSONPath, "jsonpath", "", "JSONPath expression selecting what to print from results, overriding --output")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only log errors")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "log more details, repeat for debug logging")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log format, either text or json")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "write logs to this file instead of stderr")
+	rootCmd.PersistentFlags().BoolVar(&trace, "trace", false, "dump every HTTP request and response to stderr, with secrets redacted")
+	rootCmd.PersistentFlags().DurationVar(&connectTimeout, "connect-timeout", 10*time.Second, "how long to wait for a connection to the server")
+	rootCmd.PersistentFlags().DurationVar(&readTimeout, "read-timeout", 30*time.Second, "how long to wait for the server to respond")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 3, "how many times to retry GET requests that fail")
+	rootCmd.PersistentFlags().DurationVar(&retryBackoff, "retry-backoff", 200*time.Millisecond, "wait before the first retry, doubled for each retry after")
+	rootCmd.PersistentFlags().StringVar(&caCertFile, "cacert", "", "PEM bundle of CA certificates to trust in addition to the system ones")
+	rootCmd.PersistentFlags().StringVar(&clientCertFile, "cert", "", "PEM client certificate to present to the server, used with --key")
+	rootCmd.PersistentFlags().StringVar(&clientKeyFile, "key", "", "PEM private key of the client certificate")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "do not verify the server's certificate (insecure)")
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "proxy to send requests through instead of the one from HTTP_PROXY and HTTPS_PROXY")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "record every HTTP exchange to this cassette file, with secrets scrubbed")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "answer HTTP requests from this cassette file instead of the server")
+	rootCmd.PersistentFlags().StringVar(&cassetteMatch, "replay-match", matchMethodURL, "how --replay matches requests, either method-url or method-url-body")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "neither use nor fill the HTTP response cache")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "do not contact the server, working from the offline copy of lists and queueing changes until sync")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -151,7 +451,7 @@ func initConfig() {
 		// Find home directory.
 		home, err := homedir.Dir()
 		if err != nil {
-			fmt.Println(err)
+			logger.Error("finding home directory", "error", err)
 			os.Exit(1)
 		}
 
@@ -164,10 +464,10 @@ func initConfig() {
 
 	_, err := os.Stat(cfgFile)
 	if os.IsNotExist(err) {
-		var file, err = os.Create(cfgFile)
+		// The config holds the session token, so only its owner may read it.
+		var file, err = os.OpenFile(cfgFile, os.O_WRONLY|os.O_CREATE, 0600)
 		if err != nil {
-			fmt.Println("Error creating config file: ", cfgFile)
-			fmt.Println(err)
+			logger.Error("creating config file", "path", cfgFile, "error", err)
 		}
 		defer file.Close()
 	}
@@ -175,11 +475,25 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		logger.Info("using config file", "path", viper.ConfigFileUsed())
 	}
 	viper.Set("server-url", serverUrl)
-	err = viper.WriteConfig()
+	err = writeConfig()
 	if err != nil {
-		fmt.Println(err)
+		logger.Error("writing config", "error", err)
+	}
+}
+
+// writeConfig saves the config to a temporary file beside the config file and
+// renames it into place, so an interrupted write never leaves it partial.
+func writeConfig() error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		path = cfgFile
+	}
+	var contents bytes.Buffer
+	if err := viper.WriteConfigTo(&contents); err != nil {
+		return err
 	}
+	return writeFileAtomically(path, contents.Bytes(), 0600)
 }
@@ -0,0 +1,338 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var scanDryRun bool
+
+// scanCmd represents the scan command
+var scanCmd = &cobra.Command{
+	Use:   "scan <dir>",
+	Short: "Add a todo for each TODO and FIXME comment in a source tree",
+	Long: `Walk a directory, skipping what .gitignore files exclude, and add a todo to
+the list for each TODO or FIXME comment in the source files of common
+languages. Each todo names the file and line of its comment and ends with a
+fingerprint of the comment, so running scan again only adds the comments
+that are new, even when the ones already added have moved to other lines or
+scan is run on another directory of the same repository.
+Comments whose todos were completed are not added again either.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		comments, err := scanTree(args[0])
+		if err != nil {
+			logger.Error("scanning", "dir", args[0], "error", err)
+			return
+		}
+		result := ScanResult{DryRun: scanDryRun, Comments: make([]ScannedComment, 0, len(comments))}
+		name := selectedListName()
+		known, err := knownFingerprints(ctx, name)
+		if err != nil {
+			logger.Error("request failed", "error", err)
+			return
+		}
+		for _, comment := range comments {
+			if known[comment.Fingerprint] {
+				comment.Exists = true
+				result.Comments = append(result.Comments, comment)
+				continue
+			}
+			if scanDryRun {
+				result.Comments = append(result.Comments, comment)
+				continue
+			}
+			list, err := fetchList(ctx, name)
+			if err == nil {
+				err = sendOperation(ctx, list.List, QueuedOperation{Action: "add", Later: laterTodos, Task: comment.Task()})
+			}
+			if err != nil {
+				render(result)
+				logger.Error("scan stopped, run it again to continue", "error", err)
+				return
+			}
+			known[comment.Fingerprint] = true
+			result.Comments = append(result.Comments, comment)
+		}
+		render(result)
+	},
+}
+
+// ScannedComment is a TODO or FIXME comment found by scan. Exists is true
+// when the list already had a todo for it.
+type ScannedComment struct {
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Kind        string `json:"kind"`
+	Text        string `json:"text"`
+	Fingerprint string `json:"fingerprint"`
+	Exists      bool   `json:"exists"`
+}
+
+// Task is the todo added for a comment.
+func (comment ScannedComment) Task() string {
+	task := comment.Kind
+	if comment.Text != "" {
+		task += ": " + comment.Text
+	}
+	return fmt.Sprintf("%s (%s:%d) [%s]", task, comment.File, comment.Line, comment.Fingerprint)
+}
+
+var (
+	todoComment     = regexp.MustCompile(`\b(TODO|FIXME)\b(\([^)]*\))?:?\s*(.*)$`)
+	taskFingerprint = regexp.MustCompile(`\[([0-9a-f]{12})\]$`)
+)
+
+// commentMarkers are how comments start in each language scan knows,
+// keyed by file extension.
+var commentMarkers = languageCommentMarkers()
+
+// blockComments are the comment markers that run until a closing marker
+// rather than to the end of the line.
+var blockComments = map[string]string{"/*": "*/", "<!--": "-->", "{-": "-}"}
+
+func languageCommentMarkers() map[string][]string {
+	languages := []struct {
+		extensions []string
+		markers    []string
+	}{
+		{[]string{".go", ".js", ".jsx", ".ts", ".tsx", ".java", ".c", ".h", ".cc", ".cpp", ".hpp", ".cs", ".rs", ".swift", ".kt", ".scala", ".dart", ".scss", ".groovy"}, []string{"//", "/*"}},
+		{[]string{".php"}, []string{"//", "#", "/*"}},
+		{[]string{".css"}, []string{"/*"}},
+		{[]string{".py", ".rb", ".sh", ".bash", ".zsh", ".pl", ".r", ".ex", ".exs", ".yml", ".yaml", ".toml", ".tf", ".cmake"}, []string{"#"}},
+		{[]string{".sql", ".lua"}, []string{"--", "/*"}},
+		{[]string{".hs"}, []string{"--", "{-"}},
+		{[]string{".html", ".xml", ".md", ".vue", ".svelte"}, []string{"<!--"}},
+		{[]string{".el", ".clj", ".lisp", ".scm", ".ini"}, []string{";"}},
+	}
+	markers := make(map[string][]string)
+	for _, language := range languages {
+		for _, extension := range language.extensions {
+			markers[extension] = language.markers
+		}
+	}
+	return markers
+}
+
+// scanTree finds the TODO and FIXME comments in the files under root that
+// no .gitignore excludes, in path order.
+func scanTree(root string) ([]ScannedComment, error) {
+	absolute, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	rules := loadParentIgnoreFiles(absolute)
+	// Fingerprints use paths from the root of the repository, or of the
+	// scan outside one, so that they do not depend on where scan is run.
+	base := absolute
+	if repository, ok := repositoryRoot(absolute); ok {
+		base = repository
+	}
+	comments := make([]ScannedComment, 0)
+	err = filepath.Walk(absolute, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != absolute && (info.Name() == ".git" || rules.ignored(path, true)) {
+				return filepath.SkipDir
+			}
+			rules = append(rules, loadIgnoreFile(path)...)
+			return nil
+		}
+		markers, ok := commentMarkers[strings.ToLower(filepath.Ext(path))]
+		if !ok || !info.Mode().IsRegular() || rules.ignored(path, false) {
+			return nil
+		}
+		relative, err := filepath.Rel(absolute, path)
+		if err != nil {
+			return err
+		}
+		fromBase, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		found, err := scanFile(path, filepath.ToSlash(filepath.Join(root, relative)), filepath.ToSlash(fromBase), markers)
+		if err != nil {
+			logger.Warn("skipping unreadable file", "path", path, "error", err)
+			return nil
+		}
+		comments = append(comments, found...)
+		return nil
+	})
+	return comments, err
+}
+
+// scanFile finds the TODO and FIXME comments in a file, naming it by name and
+// fingerprinting them with relative, its path from the repository root.
+func scanFile(path string, name string, relative string, markers []string) ([]ScannedComment, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(contents, 0) >= 0 {
+		return nil, nil
+	}
+	comments := make([]ScannedComment, 0)
+	occurrences := make(map[string]int)
+	closing := ""
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(make([]byte, 64*1024), len(contents)+1)
+	for number := 1; scanner.Scan(); number++ {
+		var comment string
+		comment, closing = commentText(scanner.Text(), markers, closing)
+		match := todoComment.FindStringSubmatch(comment)
+		if match == nil {
+			continue
+		}
+		text := strings.TrimSpace(match[3])
+		// The same comment can appear more than once in a file, so each
+		// occurrence is fingerprinted with how many came before it.
+		key := match[1] + "\n" + text
+		occurrences[key]++
+		comments = append(comments, ScannedComment{
+			File:        name,
+			Line:        number,
+			Kind:        match[1],
+			Text:        text,
+			Fingerprint: commentFingerprint(relative, key, occurrences[key]),
+		})
+	}
+	return comments, scanner.Err()
+}
+
+// commentText returns the comment on a line, given the marker closing the
+// block comment the line starts in, if any, and returns the marker closing
+// the block comment still open at the end of the line.
+func commentText(line string, markers []string, closing string) (string, string) {
+	if closing != "" {
+		if end := strings.Index(line, closing); end >= 0 {
+			return line[:end], ""
+		}
+		return line, closing
+	}
+	start, marker := -1, ""
+	for _, candidate := range markers {
+		if index := strings.Index(line, candidate); index >= 0 && (start < 0 || index < start) {
+			start, marker = index, candidate
+		}
+	}
+	if start < 0 {
+		return "", ""
+	}
+	rest := line[start+len(marker):]
+	if close, ok := blockComments[marker]; ok {
+		if end := strings.Index(rest, close); end >= 0 {
+			return rest[:end], ""
+		}
+		return rest, close
+	}
+	return rest, ""
+}
+
+// commentFingerprint identifies a comment by its file, its text and which
+// occurrence of that text in the file it is, but not its line, so that it
+// survives the lines around it changing.
+func commentFingerprint(file string, key string, occurrence int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%d", file, key, occurrence)))
+	return hex.EncodeToString(sum[:6])
+}
+
+// knownFingerprints collects the fingerprints of the todos of a list, both
+// open and completed.
+func knownFingerprints(ctx context.Context, name string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	list, err := fetchList(ctx, name)
+	if err != nil {
+		return known, err
+	}
+	completed, err := fetchCompletedTodos(ctx, completedLink(ctx, name, list.List), time.Time{}, time.Time{})
+	if err != nil {
+		return known, err
+	}
+	all := append(tasks(list.List.Todos), tasks(list.List.DeferredTodos)...)
+	for _, task := range append(all, completedTasks(completed)...) {
+		if match := taskFingerprint.FindStringSubmatch(task); match != nil {
+			known[match[1]] = true
+		}
+	}
+	return known, nil
+}
+
+// ScanResult is every comment scan found, with whether a todo was added for
+// it, or would be with --dry-run.
+type ScanResult struct {
+	DryRun   bool             `json:"dryRun"`
+	Comments []ScannedComment `json:"comments"`
+}
+
+func (result ScanResult) String() string {
+	return result.text(terminal{})
+}
+
+func (result ScanResult) text(t terminal) string {
+	var builder strings.Builder
+	added := 0
+	for _, comment := range result.Comments {
+		if comment.Exists {
+			fmt.Fprintf(&builder, "%s\n", t.paint(colorGray, "  "+comment.Task()))
+			continue
+		}
+		added++
+		fmt.Fprintf(&builder, "%s %s\n", t.paint(colorGreen, "+"), comment.Task())
+	}
+	verb := "Added"
+	if result.DryRun {
+		verb = "Would add"
+	}
+	fmt.Fprintf(&builder, "%s %d todos, %d already in the list\n", verb, added, len(result.Comments)-added)
+	return builder.String()
+}
+
+func (result ScanResult) Columns() []string {
+	return []string{"file", "line", "kind", "text", "fingerprint", "exists"}
+}
+
+func (result ScanResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Comments))
+	for _, comment := range result.Comments {
+		rows = append(rows, []string{comment.File, fmt.Sprint(comment.Line), comment.Kind, comment.Text, comment.Fingerprint, fmt.Sprint(comment.Exists)})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+
+	addListFlag(scanCmd)
+	addLaterFlag(scanCmd)
+	scanCmd.Flags().BoolVar(&scanDryRun, "dry-run", false, "show the todos that would be added without adding them")
+}
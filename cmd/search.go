@@ -0,0 +1,173 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	searchRegex      bool
+	searchIgnoreCase bool
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Find todos in the now, later and completed lists",
+	Long: `Find todos whose task contains the query in the now, later and completed
+lists. With --regex the query is a regular expression, and with
+--ignore-case letter case is ignored.
+
+When the server advertises a templated search link it is used for plain
+substring searches; otherwise the lists are fetched and searched locally.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		match, err := searchMatcher(args[0], searchRegex, searchIgnoreCase)
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		var results []SearchResult
+		rootResources := getRootResources(ctx, Link{Href: viper.GetString("root-href")})
+		if link, ok := rootResources.Links["search"]; ok && link.Templated && !searchRegex && selectedListName() == "" {
+			results = searchRemotely(ctx, link, args[0], searchIgnoreCase)
+		} else {
+			results = searchLocally(ctx, match)
+		}
+		render(SearchResults{Todos: results})
+	},
+}
+
+// SearchResults are the todos matching a search along with the list each
+// was found in.
+type SearchResults struct {
+	Todos []SearchResult `json:"todos"`
+}
+
+func (result SearchResults) String() string {
+	return result.text(terminal{})
+}
+
+// text aligns the list names in a column, like the table form without a
+// header, coloring each by the kind of list it is.
+func (result SearchResults) text(t terminal) string {
+	width := 0
+	for _, todo := range result.Todos {
+		if len(todo.List) > width {
+			width = len(todo.List)
+		}
+	}
+	var builder strings.Builder
+	for _, todo := range result.Todos {
+		padding := strings.Repeat(" ", width-len(todo.List)+2)
+		fmt.Fprintf(&builder, "%s%s%s\n", t.paint(todo.color(), todo.List), padding, t.wrap(todo.Task, width+2))
+	}
+	return builder.String()
+}
+
+func (result SearchResults) Columns() []string {
+	return []string{"list", "task"}
+}
+
+func (result SearchResults) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Todos))
+	for _, todo := range result.Todos {
+		rows = append(rows, []string{todo.List, todo.Task})
+	}
+	return rows
+}
+
+func (result SearchResult) color() string {
+	switch {
+	case result.List == "completed":
+		return colorGray
+	case result.deferred || result.List == "later":
+		return colorBlue
+	default:
+		return colorGreen
+	}
+}
+
+func searchMatcher(query string, regex bool, ignoreCase bool) (func(string) bool, error) {
+	if regex {
+		if ignoreCase {
+			query = "(?i)" + query
+		}
+		expression, err := regexp.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+		return expression.MatchString, nil
+	}
+	if ignoreCase {
+		query = strings.ToLower(query)
+		return func(task string) bool {
+			return strings.Contains(strings.ToLower(task), query)
+		}, nil
+	}
+	return func(task string) bool {
+		return strings.Contains(task, query)
+	}, nil
+}
+
+func searchRemotely(ctx context.Context, link Link, query string, ignoreCase bool) []SearchResult {
+	values := make(map[string]string)
+	values["query"] = query
+	if ignoreCase {
+		values["ignoreCase"] = "true"
+	}
+	var searchResponse SearchResponse
+	getResource(ctx, expandLink(link, values), &searchResponse)
+	return searchResponse.Todos
+}
+
+func searchLocally(ctx context.Context, match func(string) bool) []SearchResult {
+	results := make([]SearchResult, 0)
+	list := getList(ctx).List
+	for _, todo := range list.Todos {
+		if match(todo.Task) {
+			results = append(results, SearchResult{Task: todo.Task, List: nowName(list)})
+		}
+	}
+	for _, todo := range list.DeferredTodos {
+		if match(todo.Task) {
+			results = append(results, SearchResult{Task: todo.Task, List: deferredName(list), deferred: true})
+		}
+	}
+	for _, todo := range getCompletedTodos(ctx, completedListLink(ctx), time.Time{}, time.Time{}) {
+		if match(todo.Task) {
+			results = append(results, SearchResult{Task: todo.Task, List: "completed"})
+		}
+	}
+	return results
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	addListFlag(searchCmd)
+	searchCmd.Flags().BoolVar(&searchRegex, "regex", false, "treat the query as a regular expression")
+	searchCmd.Flags().BoolVarP(&searchIgnoreCase, "ignore-case", "i", false, "ignore letter case when matching")
+}
@@ -18,11 +18,12 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
-	"net/http"
 	"fmt"
+	"net/http"
 	"github.com/spf13/viper"
 	"os"
 	"bufio"
+	"context"
 	"github.com/spf13/cobra"
 )
 
@@ -37,43 +38,51 @@ Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		resourcesResponse := getBaseResources(Link{Href: viper.GetString("server-url") + "/v1/"})
+		ctx := cmd.Context()
+		resourcesResponse := getBaseResources(ctx, Link{Href: apiBaseURL() + "/v1/"})
 		scanner := bufio.NewScanner(os.Stdin)
-		signup(scanner, resourcesResponse.Links["signup"].Href)
+		signup(ctx, scanner, resourcesResponse.Links["signup"].Href)
 	},
 }
 
-func signup(scanner *bufio.Scanner, url string) {
+func signup(ctx context.Context, scanner *bufio.Scanner, url string) {
 	form := make(map[string]interface{})
-	fmt.Print("Email: ")
+	fmt.Fprint(promptWriter(), "Email: ")
 	scanner.Scan()
 	emailResult := scanner.Text()
 	form["email"] = emailResult
-	fmt.Print("Password: ")
+	fmt.Fprint(promptWriter(), "Password: ")
 	scanner.Scan()
 	passwordResult := scanner.Text()
-	fmt.Print("Password Confirmation: ")
+	fmt.Fprint(promptWriter(), "Password Confirmation: ")
 	scanner.Scan()
 	passwordConfirmationResult := scanner.Text()
 	if passwordResult != passwordConfirmationResult {
-		fmt.Println("Password confirmation and password do not match.")
+		render(MessageResult{Message: "Password confirmation and password do not match."})
 		return
 	}
 	form["password"] = passwordResult
-	httpClient := &http.Client{}
 	jsonData, _ := json.Marshal(form)
-	response, _ := httpClient.Post(url, "application/json", bytes.NewReader(jsonData))
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+	req.Header.Add("Content-Type", "application/json")
+	response, err := httpClient().Do(req)
+	if err != nil {
+		logger.Error("request failed", "method", "POST", "url", url, "error", err)
+		return
+	}
+	defer response.Body.Close()
 	var SessionResponse SessionResponse
 	jsonParseErr := json.NewDecoder(response.Body).Decode(&SessionResponse)
 	if jsonParseErr != nil {
-		fmt.Println(jsonParseErr)
+		logger.Error("decoding session response", "url", url, "error", jsonParseErr)
 	}
 	viper.Set("session-token", SessionResponse.Session.Token)
 	viper.Set("root-href", SessionResponse.Links["root"].Href)
-	err := viper.WriteConfig()
+	err = writeConfig()
 	if err != nil {
-		fmt.Println(err)
+		logger.Error("writing config", "error", err)
 	}
+	render(SessionResult{RootHref: SessionResponse.Links["root"].Href})
 }
 
 func init() {
@@ -0,0 +1,170 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Send the changes queued while offline to the server",
+	Long: `Send the changes made while offline to the server in the order they were
+made. Changes that no longer apply, such as completing a todo that was
+completed elsewhere, are reported and dropped. A change to a todo that was
+also changed on the server is a conflict, resolved as --strategy says or by
+asking. If the server cannot be reached or refuses a change for any other
+reason, or a conflict is aborted, that change and the ones after it stay
+queued for the next sync.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		if err := checkStrategy(); err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		operations, err := readQueue()
+		if err != nil {
+			logger.Error("reading operation queue", "error", err)
+			return
+		}
+		result := SyncResult{
+			Applied: make([]QueuedOperation, 0),
+			Skipped: make([]SkippedOperation, 0),
+			Pending: make([]QueuedOperation, 0),
+		}
+		touched := make(map[string]bool)
+		for i, operation := range operations {
+			list, err := fetchList(ctx, operation.List)
+			resolution := strategyMine
+			if err == nil {
+				if found := detectConflict(operation, list.List); found != nil {
+					resolution = resolveConflict(found)
+				}
+			}
+			if resolution == strategyAbort {
+				result.Pending = operations[i:]
+				break
+			}
+			if err == nil && resolution == strategyMine {
+				_, err = sendListMutation(ctx, operation.List, list, func(current List) error {
+					return sendOperation(ctx, current, operation)
+				})
+			}
+			if errors.Is(err, errNotRetried) && conflictStrategy == strategyTheirs {
+				resolution, err = strategyTheirs, nil
+			}
+			// Only a change that no longer applies is dropped. Anything
+			// else, such as an expired session, may succeed on a later sync.
+			var notApplicableErr *notApplicableError
+			if (err != nil && !errors.As(err, &notApplicableErr)) || ctx.Err() != nil {
+				if isUnreachable(err) {
+					logger.Warn("stopping sync", "error", err)
+				} else {
+					logger.Error("stopping sync", "error", err)
+				}
+				result.Pending = operations[i:]
+				break
+			}
+			if resolution == strategyTheirs {
+				result.Skipped = append(result.Skipped, SkippedOperation{Operation: operation, Reason: "kept the server's version"})
+			} else if err != nil {
+				result.Skipped = append(result.Skipped, SkippedOperation{Operation: operation, Reason: err.Error()})
+			} else {
+				result.Applied = append(result.Applied, operation)
+			}
+			touched[operation.List] = true
+			// Dropping each operation as soon as it is sent means an
+			// interrupted sync never sends it twice.
+			if err := writeQueue(operations[i+1:]); err != nil {
+				logger.Error("writing operation queue", "error", err)
+				return
+			}
+		}
+		for name := range touched {
+			if _, err := fetchList(ctx, name); err != nil {
+				logger.Warn("refreshing offline copy", "list", name, "error", err)
+			}
+		}
+		render(result)
+	},
+}
+
+// SyncResult is what became of each queued operation. Pending operations
+// were not applied because the server could not be reached or refused one,
+// or a conflict was aborted.
+type SyncResult struct {
+	Applied []QueuedOperation  `json:"applied"`
+	Skipped []SkippedOperation `json:"skipped"`
+	Pending []QueuedOperation  `json:"pending"`
+}
+
+// SkippedOperation is a queued operation that no longer applies.
+type SkippedOperation struct {
+	Operation QueuedOperation `json:"operation"`
+	Reason    string          `json:"reason"`
+}
+
+func (result SyncResult) String() string {
+	return result.text(terminal{})
+}
+
+func (result SyncResult) text(t terminal) string {
+	if len(result.Applied)+len(result.Skipped)+len(result.Pending) == 0 {
+		return "Nothing to sync\n"
+	}
+	var builder strings.Builder
+	for _, row := range result.Rows() {
+		switch row[0] {
+		case "applied":
+			fmt.Fprintf(&builder, "%s %s\n", t.paint(colorGreen, "applied"), row[1])
+		case "skipped":
+			fmt.Fprintf(&builder, "%s %s: %s\n", t.paint(colorYellow, "skipped"), row[1], row[2])
+		default:
+			fmt.Fprintf(&builder, "%s %s\n", t.paint(colorGray, "pending"), row[1])
+		}
+	}
+	return builder.String()
+}
+
+func (result SyncResult) Columns() []string {
+	return []string{"status", "operation", "reason"}
+}
+
+func (result SyncResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Applied)+len(result.Skipped)+len(result.Pending))
+	for _, operation := range result.Applied {
+		rows = append(rows, []string{"applied", operation.String(), ""})
+	}
+	for _, skipped := range result.Skipped {
+		rows = append(rows, []string{"skipped", skipped.Operation.String(), skipped.Reason})
+	}
+	for _, operation := range result.Pending {
+		rows = append(rows, []string{"pending", operation.String(), ""})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	addStrategyFlag(syncCmd)
+}
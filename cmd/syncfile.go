@@ -0,0 +1,298 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// syncFileCmd represents the sync-file command
+var syncFileCmd = &cobra.Command{
+	Use:   "sync-file <file>",
+	Short: "Keep a Markdown checklist and a list in sync",
+	Long: `Bind a Markdown checklist to a list, chosen with --list on the first run, and
+bring both up to date with each other:
+
+  - a checked box completes its todo
+  - a new unchecked line adds a todo
+  - a deleted line deletes its todo
+  - a todo added on the server is added to the file
+  - a todo completed on the server has its box checked
+  - a todo deleted on the server has its line removed
+
+The binding and the todos as last synced are kept in a hidden file next to
+the checklist, so that running it again changes nothing until either side
+does. Only the now todos are synced.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		path := args[0]
+		binding, err := loadFileBinding(path)
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		result, err := syncFile(ctx, path, binding)
+		if err != nil {
+			logger.Error("sync stopped, run it again to continue", "file", path, "error", err)
+			return
+		}
+		render(result)
+	},
+}
+
+// fileBinding is what the sidecar of a synced file records: the list it is
+// bound to, empty for the default list, and the tasks both sides agreed on
+// after the last sync.
+type fileBinding struct {
+	Version  int       `json:"version"`
+	List     string    `json:"list"`
+	Todos    []string  `json:"todos"`
+	SyncedAt time.Time `json:"syncedAt"`
+}
+
+func fileBindingPath(path string) string {
+	dir, base := filepath.Split(path)
+	return filepath.Join(dir, "."+base+".doer.json")
+}
+
+// loadFileBinding reads the binding of a file, binding it to the selected
+// list when it has none yet.
+func loadFileBinding(path string) (fileBinding, error) {
+	sidecar := fileBindingPath(path)
+	contents, err := ioutil.ReadFile(sidecar)
+	if os.IsNotExist(err) {
+		return fileBinding{Version: 1, List: selectedListName(), Todos: make([]string, 0)}, nil
+	}
+	if err != nil {
+		return fileBinding{}, err
+	}
+	var binding fileBinding
+	if err := json.Unmarshal(contents, &binding); err != nil {
+		return binding, fmt.Errorf("reading %s: %v", sidecar, err)
+	}
+	if listName != "" && listName != binding.List {
+		return binding, fmt.Errorf("%s is bound to list %q, delete %s to bind it to another list", path, binding.List, sidecar)
+	}
+	return binding, nil
+}
+
+// checklistItem is a "- [ ]" or "- [x]" line of a file.
+type checklistItem struct {
+	line    int
+	task    string
+	checked bool
+}
+
+// syncFile applies the changes made to the file to the list and then the
+// changes made to the list to the file, and records what both agree on.
+func syncFile(ctx context.Context, path string, binding fileBinding) (SyncFileResult, error) {
+	result := SyncFileResult{File: path, Changes: make([]FileChange, 0)}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return result, err
+	}
+	lines := strings.Split(string(contents), "\n")
+	items := checklistItems(lines)
+	before, err := fetchList(ctx, binding.List)
+	if err != nil {
+		return result, err
+	}
+	serverTasks := tasks(before.List.Todos)
+	inFile := make(map[string]bool)
+	operations := make([]QueuedOperation, 0)
+	for _, item := range items {
+		inFile[item.task] = true
+		onServer := indexOf(serverTasks, item.task) >= 0
+		if item.checked && onServer {
+			operations = append(operations, QueuedOperation{Action: "complete", Task: item.task})
+		} else if !item.checked && !onServer && indexOf(binding.Todos, item.task) < 0 {
+			operations = append(operations, QueuedOperation{Action: "add", Task: item.task})
+		}
+	}
+	for _, task := range binding.Todos {
+		if !inFile[task] && indexOf(serverTasks, task) >= 0 {
+			operations = append(operations, QueuedOperation{Action: "delete", Task: task})
+		}
+	}
+	for _, operation := range operations {
+		list, err := fetchList(ctx, binding.List)
+		if err == nil {
+			err = sendOperation(ctx, list.List, operation)
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Changes = append(result.Changes, FileChange{Side: "list", Change: operation.Action, Task: operation.Task})
+	}
+	after, err := fetchList(ctx, binding.List)
+	if err != nil {
+		return result, err
+	}
+	serverTasks = tasks(after.List.Todos)
+
+	var completed []string
+	removed := make(map[int]bool)
+	for _, item := range items {
+		if item.checked || indexOf(serverTasks, item.task) >= 0 {
+			continue
+		}
+		if completed == nil {
+			todos, err := fetchCompletedTodos(ctx, completedLink(ctx, binding.List, after.List), time.Time{}, time.Time{})
+			if err != nil {
+				return result, err
+			}
+			completed = completedTasks(todos)
+		}
+		if indexOf(completed, item.task) >= 0 {
+			lines[item.line] = checkItem(lines[item.line])
+			result.Changes = append(result.Changes, FileChange{Side: "file", Change: "complete", Task: item.task})
+		} else {
+			removed[item.line] = true
+			result.Changes = append(result.Changes, FileChange{Side: "file", Change: "delete", Task: item.task})
+		}
+	}
+	added := make([]string, 0)
+	for _, task := range serverTasks {
+		if !inFile[task] {
+			added = append(added, "- [ ] "+task)
+			result.Changes = append(result.Changes, FileChange{Side: "file", Change: "add", Task: task})
+		}
+	}
+	updated := rewriteChecklist(lines, items, removed, added)
+	if updated != string(contents) {
+		if err := writeFileAtomically(path, []byte(updated), 0644); err != nil {
+			return result, err
+		}
+	}
+	binding.Todos = serverTasks
+	binding.SyncedAt = time.Now().UTC()
+	encoded, err := json.MarshalIndent(binding, "", "  ")
+	if err != nil {
+		return result, err
+	}
+	return result, writeFileAtomically(fileBindingPath(path), append(encoded, '\n'), 0644)
+}
+
+func checklistItems(lines []string) []checklistItem {
+	items := make([]checklistItem, 0)
+	for i, line := range lines {
+		if match := markdownCheckbox.FindStringSubmatch(line); match != nil {
+			items = append(items, checklistItem{line: i, task: strings.TrimSpace(match[2]), checked: match[1] != " "})
+		}
+	}
+	return items
+}
+
+func checkItem(line string) string {
+	match := markdownCheckbox.FindStringSubmatchIndex(line)
+	return line[:match[2]] + "x" + line[match[3]:]
+}
+
+// rewriteChecklist drops the removed lines and puts the added ones after
+// the last checklist item, or at the end when there is none.
+func rewriteChecklist(lines []string, items []checklistItem, removed map[int]bool, added []string) string {
+	insertAt := len(lines)
+	if len(items) > 0 {
+		insertAt = items[len(items)-1].line + 1
+	} else if len(lines) > 0 && lines[len(lines)-1] == "" {
+		insertAt = len(lines) - 1
+	}
+	result := make([]string, 0, len(lines)+len(added))
+	for i, line := range lines {
+		if i == insertAt {
+			result = append(result, added...)
+		}
+		if !removed[i] {
+			result = append(result, line)
+		}
+	}
+	if insertAt == len(lines) {
+		result = append(result, added...)
+	}
+	text := strings.Join(result, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text
+}
+
+// completedLink finds the completed history of the named list. Only the
+// default list falls back to the history advertised by the root resources;
+// another list without one has no history.
+func completedLink(ctx context.Context, name string, list List) Link {
+	if link, ok := list.Links["completed"]; ok || name != "" {
+		return link
+	}
+	return getRootResources(ctx, Link{Href: viper.GetString("root-href")}).Links["completedList"]
+}
+
+// SyncFileResult is each change sync-file made, to the list or to the file.
+type SyncFileResult struct {
+	File    string       `json:"file"`
+	Changes []FileChange `json:"changes"`
+}
+
+// FileChange is a todo added, completed or deleted on one side. Side is
+// "list" or "file".
+type FileChange struct {
+	Side   string `json:"side"`
+	Change string `json:"change"`
+	Task   string `json:"task"`
+}
+
+func (result SyncFileResult) String() string {
+	return result.text(terminal{})
+}
+
+func (result SyncFileResult) text(t terminal) string {
+	if len(result.Changes) == 0 {
+		return fmt.Sprintf("%s is in sync\n", result.File)
+	}
+	var builder strings.Builder
+	for _, change := range result.Changes {
+		fmt.Fprintf(&builder, "%s %s %q\n", t.paint(colorGray, change.Side+":"), change.Change, change.Task)
+	}
+	return builder.String()
+}
+
+func (result SyncFileResult) Columns() []string {
+	return []string{"side", "change", "task"}
+}
+
+func (result SyncFileResult) Rows() [][]string {
+	rows := make([][]string, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		rows = append(rows, []string{change.Side, change.Change, change.Task})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(syncFileCmd)
+
+	addListFlag(syncFileCmd)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+)
+
+var templateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		jsonData, err := json.Marshal(value)
+		return string(jsonData), err
+	},
+}
+
+// templateRenderer executes a Go template against the fields of a result,
+// so {{range .Todos}}{{.Task}}{{end}} prints the task of every todo.
+type templateRenderer struct {
+	template *template.Template
+}
+
+// newTemplateRenderer parses the given template text, or the named template
+// from the templates directory when no text is given.
+func newTemplateRenderer(text string, name string) (templateRenderer, error) {
+	if text == "" {
+		path := filepath.Join(templatesDir(), name+".tmpl")
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return templateRenderer{}, fmt.Errorf("reading template %q: %v", name, err)
+		}
+		text = string(contents)
+	}
+	parsed, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return templateRenderer{}, fmt.Errorf("parsing template: %v", err)
+	}
+	return templateRenderer{template: parsed}, nil
+}
+
+// templatesDir holds named templates, in a .doer-cli/templates directory
+// next to the config file.
+func templatesDir() string {
+	return filepath.Join(filepath.Dir(cfgFile), ".doer-cli", "templates")
+}
+
+func (renderer templateRenderer) Render(w io.Writer, result Result) error {
+	return renderer.template.Execute(w, result)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+	colorBlue   = "34"
+	colorGray   = "90"
+	colorBold   = "1"
+)
+
+var noColor bool
+
+// terminal describes how text output may be styled. The zero terminal has no
+// color and no width, which gives the plain form used when output is piped.
+type terminal struct {
+	color bool
+	width int
+}
+
+// styledResult is a result whose text form can make use of the terminal it
+// is written to. Its String method must match the plain terminal's form.
+type styledResult interface {
+	text(t terminal) string
+}
+
+// stdoutTerminal detects whether stdout is a terminal and how wide it is.
+// Color is turned off by --no-color, NO_COLOR or TERM=dumb, and can be forced
+// on with CLICOLOR_FORCE when stdout is not a terminal.
+func stdoutTerminal() terminal {
+	fd := int(os.Stdout.Fd())
+	isTerminal := term.IsTerminal(fd)
+	t := terminal{}
+	if !noColor && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" {
+		t.color = isTerminal || os.Getenv("CLICOLOR_FORCE") != ""
+	}
+	if isTerminal {
+		if width, _, err := term.GetSize(fd); err == nil && width > 0 {
+			t.width = width
+		} else if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil {
+			t.width = width
+		}
+	}
+	return t
+}
+
+func (t terminal) paint(code string, text string) string {
+	if !t.color || text == "" {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// wrap breaks text into lines that fit the terminal after an indent of the
+// given width, indenting every line but the first. Text is left alone when
+// the width is unknown.
+func (t terminal) wrap(text string, indent int) string {
+	available := t.width - indent
+	if t.width == 0 || available < 10 || len(text) <= available {
+		return text
+	}
+	lines := make([]string, 0)
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > available {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	lines = append(lines, line)
+	return strings.Join(lines, "\n"+strings.Repeat(" ", indent))
+}
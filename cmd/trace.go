@@ -0,0 +1,154 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+var redactedHeaders = map[string]bool{
+	"Session-Token": true,
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// traceTransport dumps every request and response it carries, with secrets
+// redacted, before handing them on.
+type traceTransport struct {
+	next http.RoundTripper
+	out  io.Writer
+	mu   sync.Mutex
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	response, err := t.next.RoundTrip(req)
+	// Always in milliseconds, as a rounded Duration prints a fast response
+	// as 0s.
+	elapsed := time.Since(start).Milliseconds()
+
+	var dump strings.Builder
+	fmt.Fprintf(&dump, "> %s %s\n", req.Method, req.URL)
+	writeHeaders(&dump, "> ", req.Header)
+	writeBody(&dump, requestBody)
+	if err != nil {
+		fmt.Fprintf(&dump, "< error after %dms: %v\n", elapsed, err)
+		t.write(dump.String())
+		return response, err
+	}
+	responseBody, readErr := readBody(&response.Body)
+	fmt.Fprintf(&dump, "< %s (%dms)\n", response.Status, elapsed)
+	writeHeaders(&dump, "< ", response.Header)
+	writeBody(&dump, responseBody)
+	t.write(dump.String())
+	return response, readErr
+}
+
+func (t *traceTransport) write(dump string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	io.WriteString(t.out, dump)
+}
+
+// readBody reads a request or response body and replaces it with a copy so
+// it can still be sent or decoded.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	contents, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	*body = ioutil.NopCloser(bytes.NewReader(contents))
+	return contents, err
+}
+
+func writeHeaders(dump *strings.Builder, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			if redactedHeaders[name] {
+				value = redacted
+			}
+			fmt.Fprintf(dump, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
+
+func writeBody(dump *strings.Builder, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	dump.WriteString("\n")
+	dump.Write(redactBody(body))
+	dump.WriteString("\n\n")
+}
+
+// redactBody hides the values of password and token fields in JSON bodies.
+// Bodies that are not JSON are left as they are.
+func redactBody(body []byte) []byte {
+	var document interface{}
+	if json.Unmarshal(body, &document) != nil {
+		return body
+	}
+	result, err := json.Marshal(redactValue(document))
+	if err != nil {
+		return body
+	}
+	return result
+}
+
+func redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			if isSecretField(key) {
+				typed[key] = redacted
+			} else {
+				typed[key] = redactValue(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range typed {
+			typed[i] = redactValue(child)
+		}
+	}
+	return value
+}
+
+func isSecretField(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "password") || key == "token"
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"github.com/spf13/cobra"
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last change to the list",
+	Long: `Undo the last change made to the list, such as completing or moving a
+todo, and show how the list looked before and after.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		followListAction(ctx, "undo")
+	},
+}
+
+// followListAction posts to the named action link of the list, when the list
+// advertises it, and renders the difference it made to the list. When the
+// list changed on the server in the meantime, it offers to try again.
+func followListAction(ctx context.Context, rel string) {
+	before := getList(ctx)
+	var link Link
+	before, err := sendListMutation(ctx, selectedListName(), before, func(list List) error {
+		var ok bool
+		link, ok = list.Links[rel]
+		if !ok {
+			return notApplicable("Nothing to %s", rel)
+		}
+		return fetchResource(ctx, "POST", link, nil, nil)
+	})
+	var notApplicableErr *notApplicableError
+	switch {
+	case err == errNotRetried:
+		render(MessageResult{Message: "Nothing was changed"})
+		return
+	case errors.As(err, &notApplicableErr):
+		render(MessageResult{Message: err.Error()})
+		return
+	case err != nil:
+		logger.Error("request failed", "method", "POST", "url", link.Href, "error", err)
+	}
+	after := getList(ctx)
+	render(listDiff(rel, before.List, after.List))
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	addListFlag(undoCmd)
+}
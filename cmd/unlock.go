@@ -0,0 +1,119 @@
+/*
+Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// unlockCmd represents the unlock command
+var unlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlock the later list",
+	Long: `Unlock the later list so todos can be pulled from it, and report when
+it will lock again.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		listResponse := getList(ctx)
+		link, ok := listResponse.List.Links["unlock"]
+		if !ok {
+			render(lockStatus(listResponse.List))
+			return
+		}
+		sendResource(ctx, "POST", link, nil, nil)
+		listResponse = getList(ctx)
+		result := lockStatus(listResponse.List)
+		result.justUnlocked = true
+		render(result)
+	},
+}
+
+// LockStatusResult describes whether the deferred list is locked and, when it
+// is not, how long remains until it locks again.
+type LockStatusResult struct {
+	List             string     `json:"list"`
+	Locked           bool       `json:"locked"`
+	RemainingSeconds int64      `json:"remainingSeconds"`
+	UnlockedUntil    *time.Time `json:"unlockedUntil,omitempty"`
+	justUnlocked     bool
+}
+
+func lockStatus(list List) LockStatusResult {
+	remaining := unlockRemaining(list)
+	result := LockStatusResult{List: deferredName(list), Locked: remaining <= 0}
+	if !result.Locked {
+		until := time.Now().Add(remaining).Truncate(time.Second)
+		result.RemainingSeconds = int64(remaining / time.Second)
+		result.UnlockedUntil = &until
+	}
+	return result
+}
+
+func (result LockStatusResult) remaining() time.Duration {
+	return time.Duration(result.RemainingSeconds) * time.Second
+}
+
+func (result LockStatusResult) String() string {
+	return result.text(terminal{})
+}
+
+func (result LockStatusResult) text(t terminal) string {
+	switch {
+	case result.Locked:
+		return fmt.Sprintf("%s list: %s\n", t.paint(colorBlue, result.List), t.paint(colorRed, "locked"))
+	case result.justUnlocked:
+		return fmt.Sprintf("%s list %s for %s (until %s)\n", t.paint(colorBlue, result.List),
+			t.paint(colorGreen, "unlocked"), result.remaining(), result.UnlockedUntil.Local().Format("15:04"))
+	default:
+		return fmt.Sprintf("%s list: %s, %s remaining\n", t.paint(colorBlue, result.List),
+			t.paint(colorGreen, "unlocked"), t.paint(colorYellow, result.remaining().String()))
+	}
+}
+
+func (result LockStatusResult) Columns() []string {
+	return []string{"list", "locked", "remaining"}
+}
+
+func (result LockStatusResult) Rows() [][]string {
+	return [][]string{{result.List, fmt.Sprint(result.Locked), result.remaining().String()}}
+}
+
+func unlockRemaining(list List) time.Duration {
+	return (time.Duration(list.UnlockDuration) * time.Millisecond).Round(time.Second)
+}
+
+func nowName(list List) string {
+	if list.Name == "" {
+		return "now"
+	}
+	return list.Name
+}
+
+func deferredName(list List) string {
+	if list.DeferredName == "" {
+		return "later"
+	}
+	return list.DeferredName
+}
+
+func init() {
+	rootCmd.AddCommand(unlockCmd)
+
+	addListFlag(unlockCmd)
+}